@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/deploy"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/spf13/cobra"
+)
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Show what differs between the deployments of two environments",
+	Long: `Compare what is deployed in two environments of a repository: the commits, authors, merged pull
+requests and changed files that deploying --from to --to would bring, e.g. --from uat --to prod.
+Each environment's current deployment is taken from the deployments API.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		format, _ := cmd.Flags().GetString("format")
+		if repo == "" || from == "" || to == "" {
+			fmt.Println("Error: --repo, --from and --to flags are required")
+			return
+		}
+		if format != "text" && format != "markdown" {
+			fmt.Println("Error: --format must be text or markdown")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		source, err := currentDeployment(org, repo, from)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		target, err := currentDeployment(org, repo, to)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// What --from has that --to has not, so --to is the base
+		comparison, err := api.CompareCommits(org, repo, target.SHA, source.SHA)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("%s/%s: %s %s (%s) → %s %s (%s)\n", org, repo, from, source.Ref, shortSHA(source.SHA), to, target.Ref, shortSHA(target.SHA))
+		fmt.Printf("%s is %s by %d commit(s)", from, comparison.Status, comparison.AheadBy)
+		if comparison.BehindBy > 0 {
+			fmt.Printf(" and behind by %d", comparison.BehindBy)
+		}
+		fmt.Printf(": %s\n\n", comparison.HTMLURL)
+		if comparison.TotalCommits > len(comparison.Commits) {
+			fmt.Printf("Only the first %d of %d commits are listed\n\n", len(comparison.Commits), comparison.TotalCommits)
+		}
+
+		render := func(t table.Writer) {
+			t.SetOutputMirror(os.Stdout)
+			if format == "markdown" {
+				t.RenderMarkdown()
+			} else {
+				t.SetStyle(table.StyleColoredBlackOnYellowWhite)
+				t.Render()
+			}
+			fmt.Println()
+		}
+
+		commits := table.NewWriter()
+		commits.AppendHeader(table.Row{"Commit", "Author", "Date", "Message"})
+		authors := make(map[string]int)
+		pulls := make(map[int]api.PullRequest)
+		for _, commit := range comparison.Commits {
+			author := commit.Commit.Author.Name
+			if commit.Author != nil && commit.Author.Login != "" {
+				author = commit.Author.Login
+			}
+			authors[author]++
+			message, _, _ := strings.Cut(commit.Commit.Message, "\n")
+			commits.AppendRow(table.Row{shortSHA(commit.SHA), author, commit.Commit.Author.Date, message})
+
+			prs, err := api.FetchCommitPullRequests(org, repo, commit.SHA)
+			if err != nil {
+				fmt.Printf("Could not fetch pull requests of %s: %v\n", shortSHA(commit.SHA), err)
+				continue
+			}
+			for _, pr := range prs {
+				if pr.MergedAt != "" {
+					pulls[pr.Number] = pr
+				}
+			}
+		}
+		render(commits)
+
+		authorTable := table.NewWriter()
+		authorTable.AppendHeader(table.Row{"Author", "Commits"})
+		for _, author := range sortedByCount(authors) {
+			authorTable.AppendRow(table.Row{author, authors[author]})
+		}
+		render(authorTable)
+
+		if len(pulls) > 0 {
+			pullTable := table.NewWriter()
+			pullTable.AppendHeader(table.Row{"Pull Request", "Title", "Author", "Merged"})
+			numbers := make([]int, 0, len(pulls))
+			for number := range pulls {
+				numbers = append(numbers, number)
+			}
+			sort.Ints(numbers)
+			for _, number := range numbers {
+				pr := pulls[number]
+				pullTable.AppendRow(table.Row{fmt.Sprintf("#%d", pr.Number), pr.Title, pr.User.Login, pr.MergedAt})
+			}
+			render(pullTable)
+		}
+
+		files := table.NewWriter()
+		files.AppendHeader(table.Row{"File", "Status", "+", "-"})
+		for _, file := range comparison.Files {
+			files.AppendRow(table.Row{file.Filename, file.Status, file.Additions, file.Deletions})
+		}
+		render(files)
+	},
+}
+
+// currentDeployment returns what runs in an environment: its newest successful deployment,
+// skipping failed and unfinished ones
+func currentDeployment(org, repo, env string) (types.EnvData, error) {
+	deployments, err := api.FetchEnvironmentDeployments(org, repo, env)
+	if err != nil {
+		return types.EnvData{}, err
+	}
+	deployment, err := deploy.LastSuccessful(deployments, func(deploymentID int) ([]types.DeploymentStatus, error) {
+		return api.FetchDeploymentStatuses(org, repo, deploymentID)
+	})
+	if err != nil {
+		return types.EnvData{}, fmt.Errorf("%s/%s has no successful deployment to %s", org, repo, env)
+	}
+	return types.EnvData{
+		DeploymentID:  deployment.ID,
+		Ref:           deployment.Ref,
+		SHA:           deployment.SHA,
+		Description:   deployment.Description,
+		Status:        "success",
+		CreatedAt:     deployment.CreatedAt.Format(time.RFC3339),
+		DeploymentURL: deployment.StatusesURL,
+	}, nil
+}
+
+// sortedByCount orders names by descending count, then by name
+func sortedByCount(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().String("org", "", "Organization of the repository (default is the only organization in the config)")
+	compareCmd.Flags().String("repo", "", "Repository to compare")
+	compareCmd.Flags().String("from", "", "Environment with the newer deployment, e.g. uat")
+	compareCmd.Flags().String("to", "", "Environment to compare against, e.g. prod")
+	compareCmd.Flags().String("format", "text", "Output format. Options text, markdown")
+}
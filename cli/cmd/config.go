@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configCmd groups the commands working on the configuration file itself
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with the configuration file",
+}
+
+// configRenderCmd represents the config render command
+var configRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Print the fully resolved configuration",
+	Long:  "Print the configuration with defaults and environment templates merged into every environment, as it is used by the other commands.",
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
+			os.Exit(1)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" {
+			if err := api.WriteOutput(config, output); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		rendered, err := yaml.Marshal(config)
+		if err != nil {
+			fmt.Printf("Error rendering config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(rendered))
+	},
+}
+
+// configValidateCmd represents the config validate command
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration file for mistakes",
+	Long: `Check the configuration file for:
+		- Unknown or mis-cased keys
+		- Secret and variable names GitHub would reject (reserved GITHUB_ prefix, invalid characters)
+		- Repository, environment, secret and variable names that only differ by case
+		- Empty values and secrets still holding the import placeholder
+		- Invalid environment names`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Repository selectors are only expanded by the other commands, validation stays offline
+		_, err := config.Load(configPath())
+		if err == nil {
+			fmt.Printf("%s is valid\n", configPath())
+			return
+		}
+
+		var validationErrors config.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, validationError := range validationErrors {
+				fmt.Println(validationError)
+			}
+			fmt.Printf("\n%d problem(s) found\n", len(validationErrors))
+		} else {
+			fmt.Println(err)
+		}
+		os.Exit(1)
+	},
+}
+
+// configSchemaCmd represents the config schema command
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long:  "Print the JSON Schema of the configuration file, for editor autocompletion. A copy is kept in config.schema.json.",
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := config.Schema()
+		if err != nil {
+			fmt.Printf("Error generating schema: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(schema))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configRenderCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configRenderCmd.Flags().StringP("output", "o", "", "Write the resolved config to this file instead of stdout")
+}
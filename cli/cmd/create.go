@@ -5,15 +5,14 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"sort"
-	"strings"
+	"maps"
+	"slices"
 
 	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/state"
 	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
-	"github.com/jedib0t/go-pretty/table"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // createCmd represents the create command
@@ -23,261 +22,230 @@ var createCmd = &cobra.Command{
 	Long: `A create command for various resources. For example:
 		- Adding Deployment Environments
 		- Adding Secrets
-		- Adding Variables`,
+		- Adding Variables
+		- Adding Deployment Branch Policies
+
+--type takes a comma-separated list of resources, e.g. --type env,variables,branch-policies, or ALL.
+Each environment only gets the resources enabled by its createDeploymentEnv, createSecrets and createVariables options.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		var config types.Config
-		if err := viper.Unmarshal(&config); err != nil {
-			fmt.Printf("Error parsing config: %v\n", err)
+		// Get the type flag, --name leaves environments and branch policies alone
+		typeFlag, _ := cmd.Flags().GetString("type")
+		handlers, err := selectCreateHandlers(typeFlag, filterFromFlags(cmd))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
-		// Get the type flag
-		typeFlag, _ := cmd.Flags().GetString("type")
-		if typeFlag == "" {
-			fmt.Println("Error: --type flag is required. Options: deployment-env, ALL, secrets, variables, secrets-variables")
+		config, err := loadFilteredConfig(cmd)
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
 			return
 		}
 
-		// Define summary map
-		summary := make(map[string]string)
+		run := &createRun{
+			// Load the secret state file used to detect unchanged secrets
+			secretState: loadSecretState(cmd, config),
+			summary:     make(map[summaryKey]string),
+		}
 
-		switch typeFlag {
-		case "deployment-env":
-			for repoName, environments := range config.Repos {
-				fmt.Printf("\nRepository: %s\n", repoName)
-				for envName, envOptions := range environments {
-					if envOptions.CreateDeploymentEnv {
-						fmt.Printf("\nAttempting to create %s/%s/%s\n", config.Org, repoName, envName)
-						if status, err := api.CreateDeploymentEnv(config.Org, repoName, envName, envOptions); err != nil {
-							fmt.Printf("Error creating deployment environment for %s/%s/%s: %v\n", config.Org, repoName, envName, err)
-							summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "N/A", "N/A", "N/A")] = "error"
-						} else {
-							fmt.Printf("Successfully created deployment environment for %s/%s/%s\n", config.Org, repoName, envName)
-							summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "N/A", "N/A", "N/A")] = status
-						}
-					} else {
-						fmt.Printf("\nSkipping environment %s in repository %s as 'createDeploymentEnv' is false\n", envName, repoName)
-					}
-				}
-			}
-		case "secrets":
-			for repoName, environments := range config.Repos {
+		for _, orgName := range orgNames(config) {
+			for _, repoName := range slices.Sorted(maps.Keys(config.Orgs[orgName].Repos)) {
 				fmt.Printf("\nRepository: %s\n", repoName)
-				for envName, envOptions := range environments {
-					if envOptions.CreateSecrets {
-						fmt.Printf("\nAttempting to fetch environment public-key for %s/%s/%s\n", config.Org, repoName, envName)
-						if publicKey, err := api.GetGithubPublicKey(config.Org, repoName, envName); err != nil {
-							fmt.Printf("Error fetching public key for %s/%s/%s: %v\n", config.Org, repoName, envName, err)
-						} else {
-							fmt.Printf("Successfully fetched public key for %s/%s/%s\n", config.Org, repoName, envName)
-							fmt.Printf("\nPublic-key for %s/%s/%s: %s\n", config.Org, repoName, envName, publicKey.(map[string]interface{})["key"].(string)) // Display the public key in terminal (Debug reasons only.)
-							for secretName, secretValue := range envOptions.Secrets {
-								fmt.Printf("\nAttempting to create/update secret '%s':'%s' within %s/%s/%s\n", secretName, secretValue, config.Org, repoName, envName)
-								if status, err := api.CreateUpdateSecret(config.Org, repoName, envName, secretName, secretValue, publicKey.(map[string]interface{})["key"].(string), publicKey.(map[string]interface{})["key_id"].(string)); err != nil {
-									fmt.Printf("Error creating/updating secret %s within %s/%s/%s: %v\n", secretName, config.Org, repoName, envName, err)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "secret", secretName, secretValue)] = "error"
-								} else {
-									fmt.Printf("Successfully created/updated secret %s within %s/%s/%s\n", secretName, config.Org, repoName, envName)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "secret", secretName, secretValue)] = status
-								}
-							}
-						}
+				environments := config.Orgs[orgName].Repos[repoName]
+				for _, envName := range slices.Sorted(maps.Keys(environments)) {
+					envOptions := environments[envName]
+					if !freezeAllows(cmd, config, orgName, repoName, envName) {
+						run.record(orgName, repoName, envName, "N/A", "N/A", "N/A", "Frozen")
+						continue
 					}
-				}
-			}
-		case "variables":
-			for repoName, environments := range config.Repos {
-				fmt.Printf("\n  Repository: %s\n", repoName)
-				fmt.Println("|-----------------------------|")
-				for envName, envOptions := range environments {
-					if envOptions.CreateVariables {
-						fmt.Printf("\n  Attempting to create/update variables within %s/%s/%s\n\n", config.Org, repoName, envName)
-						for variableName, variableValue := range envOptions.Variables {
-							fmt.Println("---------------------------------------------------------------------")
-							fmt.Printf("\nAttempting to create/update variable '%s':'%s' within %s/%s/%s\n", variableName, variableValue, config.Org, repoName, envName)
-							status, err := api.CreateUpdateVariable(config.Org, repoName, envName, variableName, variableValue)
-							if err != nil {
-								fmt.Printf("Error creating/updating variable %s within %s/%s/%s: %s\n", variableName, config.Org, repoName, envName, err)
-								summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "variable", variableName, variableValue)] = "error"
-							} else {
-								summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "variable", variableName, variableValue)] = status
-							}
+					for _, handler := range handlers {
+						if !handler.enabled(envOptions) {
+							fmt.Printf("\nSkipping %s for %s/%s/%s as '%s' is false\n", handler.name, orgName, repoName, envName, handler.option)
+							continue
 						}
+						handler.create(run, orgName, repoName, envName, envOptions)
 					}
 				}
 			}
-		case "secrets-variables":
-			for repoName, environments := range config.Repos {
-				fmt.Printf("\nRepository: %s\n", repoName)
-				for envName, envOptions := range environments {
-					if envOptions.CreateSecrets && envOptions.CreateVariables {
-						fmt.Printf("\nAttempting to fetch environment public-key for %s/%s/%s\n", config.Org, repoName, envName)
-						if publicKey, err := api.GetGithubPublicKey(config.Org, repoName, envName); err != nil {
-							fmt.Printf("Error fetching public key for %s/%s/%s: %v\n", config.Org, repoName, envName, err)
-						} else {
-							fmt.Printf("Successfully fetched public key for %s/%s/%s\n", config.Org, repoName, envName)
-							fmt.Printf("\nPublic-key for %s/%s/%s: %s\n", config.Org, repoName, envName, publicKey.(map[string]interface{})["key"].(string)) // Display the public key in terminal (Debug reasons only.)
-							for secretName, secretValue := range envOptions.Secrets {
-								fmt.Println("---------------------------------------------------------------------")
-								fmt.Printf("\nAttempting to create/update secret '%s':'%s' within %s/%s/%s\n", secretName, secretValue, config.Org, repoName, envName)
-								if status, err := api.CreateUpdateSecret(config.Org, repoName, envName, secretName, secretValue, publicKey.(map[string]interface{})["key"].(string), publicKey.(map[string]interface{})["key_id"].(string)); err != nil {
-									fmt.Printf("Error creating/updating secret %s within %s/%s/%s: %v\n", secretName, config.Org, repoName, envName, err)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "secret", secretName, secretValue)] = "error"
-								} else {
-									fmt.Printf("Successfully created/updated secret %s within %s/%s/%s\n", secretName, config.Org, repoName, envName)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "secret", secretName, secretValue)] = status
-								}
-							}
-							for variableName, variableValue := range envOptions.Variables {
-								fmt.Println("---------------------------------------------------------------------")
-								fmt.Printf("\nAttempting to create/update variable '%s':'%s' within %s/%s/%s\n", variableName, variableValue, config.Org, repoName, envName)
-								status, err := api.CreateUpdateVariable(config.Org, repoName, envName, variableName, variableValue)
-								if err != nil {
-									fmt.Printf("Error creating/updating variable %s within %s/%s/%s: %s\n", variableName, config.Org, repoName, envName, err)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "variable", variableName, variableValue)] = "error"
-								} else {
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "variable", variableName, variableValue)] = status
-								}
-							}
-						}
-					}
-				}
-			}
-		case "ALL":
-			for repoName, environments := range config.Repos {
-				fmt.Printf("\nRepository: %s\n", repoName)
-				// Deployment Environments
-				for envName, envOptions := range environments {
-					if envOptions.CreateDeploymentEnv {
-						fmt.Printf("\nAttempting to create %s/%s/%s\n", config.Org, repoName, envName)
-						if status, err := api.CreateDeploymentEnv(config.Org, repoName, envName, envOptions); err != nil {
-							fmt.Printf("Error creating deployment environment for %s/%s/%s: %v\n", config.Org, repoName, envName, err)
-							summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "N.A", "N.A", "N.A")] = "error"
-						} else {
-							fmt.Printf("Successfully created deployment environment for %s/%s/%s\n", config.Org, repoName, envName)
-							summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "N.A", "N.A", "N.A")] = status
-						}
-					} else {
-						fmt.Printf("\nSkipping environment %s in repository %s as 'createDeploymentEnv' is false\n", envName, repoName)
-					}
-					// Secrets
-					if envOptions.CreateSecrets {
-						fmt.Printf("\nAttempting to fetch environment public-key for %s/%s/%s\n", config.Org, repoName, envName)
-						if publicKey, err := api.GetGithubPublicKey(config.Org, repoName, envName); err != nil {
-							fmt.Printf("Error fetching public key for %s/%s/%s: %v\n", config.Org, repoName, envName, err)
-						} else {
-							fmt.Printf("Successfully fetched public key for %s/%s/%s\n", config.Org, repoName, envName)
-							fmt.Printf("\nPublic-key for %s/%s/%s: %s\n", config.Org, repoName, envName, publicKey.(map[string]interface{})["key"].(string)) // Display the public key in terminal (Debug reasons only.)
-							for secretName, secretValue := range envOptions.Secrets {
-								fmt.Printf("\nAttempting to create/update secret '%s':'%s' within %s/%s/%s\n", secretName, secretValue, config.Org, repoName, envName)
-								if status, err := api.CreateUpdateSecret(config.Org, repoName, envName, secretName, secretValue, publicKey.(map[string]interface{})["key"].(string), publicKey.(map[string]interface{})["key_id"].(string)); err != nil {
-									fmt.Printf("Error creating/updating secret %s within %s/%s/%s: %v\n", secretName, config.Org, repoName, envName, err)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "secret", secretName, secretValue)] = "error"
-								} else {
-									fmt.Printf("Successfully created/updated secret %s within %s/%s/%s\n", secretName, config.Org, repoName, envName)
-									summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "secret", secretName, secretValue)] = status
-								}
-							}
-						}
-					}
-					// Variables
-					if envOptions.CreateVariables {
-						fmt.Printf("\n  Attempting to create/update variables within %s/%s/%s\n\n", config.Org, repoName, envName)
-						for variableName, variableValue := range envOptions.Variables {
-							fmt.Println("---------------------------------------------------------------------")
-							fmt.Printf("\nAttempting to create/update variable '%s':'%s' within %s/%s/%s\n", variableName, variableValue, config.Org, repoName, envName)
-							status, err := api.CreateUpdateVariable(config.Org, repoName, envName, variableName, variableValue)
-							if err != nil {
-								fmt.Printf("Error creating/updating variable %s within %s/%s/%s: %s\n", variableName, config.Org, repoName, envName, err)
-								summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "variable", variableName, variableValue)] = "error"
-							} else {
-								summary[fmt.Sprintf(" %s/%s/%s/%s/%s/%s", config.Org, repoName, envName, "variable", variableName, variableValue)] = status
-							}
-						}
-					}
-				}
+		}
+		if run.secretState != nil {
+			if err := run.secretState.Save(); err != nil {
+				fmt.Printf("Error saving secret state: %v\n", err)
 			}
 		}
-		// Print summary
-		fmt.Printf("\n\n\n")
 
-		// Collect summary entries into a slice for sorting
-		var summaryEntries []struct {
-			Org         string
-			Repo        string
-			Env         string
-			VarOrSecret string
-			Name        string
-			Value       string
-			Status      string
-		}
+		printSummary(run.summary)
+
+	},
+}
 
-		for item, status := range summary {
-			parts := strings.Split(item, "/")
-			if len(parts) >= 5 {
-				org := parts[0]
-				repo := parts[1]
-				env := parts[2]
-				varOrSecret := parts[3]
-				name := parts[4]
-				value := parts[5]
+// createRun holds what the create handlers share during one run
+type createRun struct {
+	secretState *state.State
+	summary     map[summaryKey]string
+}
 
-				summaryEntries = append(summaryEntries, struct {
-					Org         string
-					Repo        string
-					Env         string
-					VarOrSecret string
-					Name        string
-					Value       string
-					Status      string
-				}{
-					Org:         org,
-					Repo:        repo,
-					Env:         env,
-					VarOrSecret: varOrSecret,
-					Name:        name,
-					Value:       value,
-					Status:      status,
-				})
-			}
+// record adds a result to the summary, keyed as printSummary expects
+func (r *createRun) record(org, repo, env, kind, name, value, status string) {
+	r.summary[summaryKey{org, repo, env, kind, name, value}] = status
+}
+
+// createHandler creates one kind of resource in an environment
+type createHandler struct {
+	name    string
+	option  string // config option that enables the handler per environment
+	enabled func(envOptions types.DeploymentEnvOptions) bool
+	create  func(run *createRun, org, repo, env string, envOptions types.DeploymentEnvOptions)
+}
+
+// createHandlers creates each of config.Resources, by resource name
+var createHandlers = map[string]createHandler{
+	"env": {
+		option:  "createDeploymentEnv",
+		enabled: func(envOptions types.DeploymentEnvOptions) bool { return envOptions.CreateDeploymentEnv },
+		create:  createEnvironment,
+	},
+	"secrets": {
+		option:  "createSecrets",
+		enabled: func(envOptions types.DeploymentEnvOptions) bool { return envOptions.CreateSecrets },
+		create:  createSecrets,
+	},
+	"variables": {
+		option:  "createVariables",
+		enabled: func(envOptions types.DeploymentEnvOptions) bool { return envOptions.CreateVariables },
+		create:  createVariables,
+	},
+	"branch-policies": {
+		option:  "createDeploymentEnv",
+		enabled: func(envOptions types.DeploymentEnvOptions) bool { return envOptions.CreateDeploymentEnv },
+		create:  createBranchPolicies,
+	},
+}
+
+// selectCreateHandlers returns the handlers of the resources selected by the --type flag and the filter, in creation order
+func selectCreateHandlers(typeFlag string, filter config.Filter) ([]createHandler, error) {
+	resources, err := config.SelectResources(typeFlag, filter)
+	if err != nil {
+		return nil, err
+	}
+	var handlers []createHandler
+	for _, resource := range resources {
+		handler := createHandlers[resource]
+		handler.name = resource
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
+
+func createEnvironment(run *createRun, org, repo, env string, envOptions types.DeploymentEnvOptions) {
+	fmt.Printf("\nAttempting to create %s/%s/%s\n", org, repo, env)
+	status, err := api.CreateDeploymentEnv(org, repo, env, envOptions)
+	if err != nil {
+		fmt.Printf("Error creating deployment environment for %s/%s/%s: %v\n", org, repo, env, err)
+		status = "error"
+	} else {
+		fmt.Printf("Successfully created deployment environment for %s/%s/%s\n", org, repo, env)
+	}
+	run.record(org, repo, env, "N/A", "N/A", "N/A", status)
+}
+
+func createSecrets(run *createRun, org, repo, env string, envOptions types.DeploymentEnvOptions) {
+	if len(envOptions.Secrets) == 0 {
+		return
+	}
+	fmt.Printf("\nAttempting to fetch environment public-key for %s/%s/%s\n", org, repo, env)
+	publicKey, err := api.GetGithubPublicKey(org, repo, env)
+	if err != nil {
+		fmt.Printf("Error fetching public key for %s/%s/%s: %v\n", org, repo, env, err)
+		for secretName, secretValue := range envOptions.Secrets {
+			run.record(org, repo, env, "secret", secretName, secretValue, "error")
 		}
-		// Sort the summary entries by org, repo, env, and var/secret
-		sort.Slice(summaryEntries, func(i, j int) bool {
-			if summaryEntries[i].Repo != summaryEntries[j].Repo {
-				return summaryEntries[i].Repo < summaryEntries[j].Repo
-			}
-			if summaryEntries[i].Env != summaryEntries[j].Env {
-				return summaryEntries[i].Env < summaryEntries[j].Env
-			}
-			return summaryEntries[i].VarOrSecret < summaryEntries[j].VarOrSecret
-		})
+		return
+	}
+	key := publicKey.(map[string]interface{})["key"].(string)
+	keyID := publicKey.(map[string]interface{})["key_id"].(string)
+	fmt.Printf("Successfully fetched public key for %s/%s/%s\n", org, repo, env)
 
-		// Print summary using table package for better outputformatting
-		t := table.NewWriter()
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"Org", "Repo", "Environment", "Var/Secret", "Name", "Value", "Status"})
+	for _, secretName := range slices.Sorted(maps.Keys(envOptions.Secrets)) {
+		secretValue := envOptions.Secrets[secretName]
+		fmt.Println("---------------------------------------------------------------------")
+		fmt.Printf("\nAttempting to create/update secret '%s' within %s/%s/%s\n", secretName, org, repo, env)
+		status, err := api.CreateUpdateSecretIfChanged(org, repo, env, secretName, secretValue, key, keyID, run.secretState)
+		if err != nil {
+			fmt.Printf("Error creating/updating secret %s within %s/%s/%s: %v\n", secretName, org, repo, env, err)
+			status = "error"
+		} else {
+			fmt.Printf("Successfully created/updated secret %s within %s/%s/%s\n", secretName, org, repo, env)
+		}
+		run.record(org, repo, env, "secret", secretName, secretValue, status)
+	}
+}
 
-		for _, entry := range summaryEntries {
-			status := entry.Status
-			if status == "error" {
-				status = fmt.Sprintf("%s ❌", status)
-			} else if status == "Unchanged" {
-				status = fmt.Sprintf("%s 🆗", status)
-			} else if status == "Changed" {
-				status = fmt.Sprintf("%s 💣", status)
-			} else if status == "Created" {
-				status = fmt.Sprintf("%s ✅", status)
-			} else if status == "Created&Updated" {
-				status = fmt.Sprintf("%s 🔄", status) // This is mainly for Deployment Environments are Create and Update are the same PUT operation on the same API
-			}
-			t.AppendRow([]interface{}{entry.Org, entry.Repo, entry.Env, entry.VarOrSecret, entry.Name, entry.Value, status})
+func createVariables(run *createRun, org, repo, env string, envOptions types.DeploymentEnvOptions) {
+	fmt.Printf("\n  Attempting to create/update variables within %s/%s/%s\n\n", org, repo, env)
+	for _, variableName := range slices.Sorted(maps.Keys(envOptions.Variables)) {
+		variableValue := envOptions.Variables[variableName]
+		fmt.Println("---------------------------------------------------------------------")
+		fmt.Printf("\nAttempting to create/update variable '%s':'%s' within %s/%s/%s\n", variableName, variableValue, org, repo, env)
+		status, err := api.CreateUpdateVariable(org, repo, env, variableName, variableValue)
+		if err != nil {
+			fmt.Printf("Error creating/updating variable %s within %s/%s/%s: %s\n", variableName, org, repo, env, err)
+			status = "error"
 		}
-		t.SetStyle(table.StyleColoredBlackOnYellowWhite)
-		t.Render()
+		run.record(org, repo, env, "variable", variableName, variableValue, status)
+	}
+}
 
-	},
+func createBranchPolicies(run *createRun, org, repo, env string, envOptions types.DeploymentEnvOptions) {
+	if len(envOptions.BranchPolicies) == 0 {
+		return
+	}
+	existing, err := api.ListBranchPolicies(org, repo, env)
+	if err != nil {
+		fmt.Printf("Error listing branch policies for %s/%s/%s: %v\n", org, repo, env, err)
+		for _, policy := range envOptions.BranchPolicies {
+			run.record(org, repo, env, "branch-policy", policy.Name, policy.Type, "error")
+		}
+		return
+	}
+	for _, policy := range envOptions.BranchPolicies {
+		status, err := api.CreateBranchPolicy(org, repo, env, policy, existing)
+		if err != nil {
+			fmt.Printf("Error creating branch policy %s within %s/%s/%s: %v\n", policy.Name, org, repo, env, err)
+			status = "error"
+		}
+		policyType := policy.Type
+		if policyType == "" {
+			policyType = "branch"
+		}
+		run.record(org, repo, env, "branch-policy", policy.Name, policyType, status)
+	}
 }
 
+// loadSecretState loads the secret fingerprint state file, falling back to reporting every
+// existing secret as Changed when no state key is configured.
+func loadSecretState(cmd *cobra.Command, config types.Config) *state.State {
+	stateFile, _ := cmd.Flags().GetString("state-file")
+	if stateFile == "" {
+		stateFile = config.StateFile
+	}
+	if stateFile == "" {
+		stateFile = defaultStateFile
+	}
+
+	secretState, err := state.Load(stateFile)
+	if err != nil {
+		fmt.Printf("Secret change detection disabled: %v\n", err)
+		return nil
+	}
+	return secretState
+}
+
+const defaultStateFile = ".autogit-state.json"
+
 func init() {
 	rootCmd.AddCommand(createCmd)
-	createCmd.Flags().StringP("type", "t", "", "Type of resource to create. Options repository, secret, variable")
+	addFilterFlags(createCmd)
+	addFreezeFlags(createCmd)
+	createCmd.Flags().StringP("type", "t", "", "Comma-separated resources to create. Options ALL, env, secrets, variables, branch-policies")
+	createCmd.Flags().String("state-file", "", "Secret fingerprint state file (default is stateFile from config or "+defaultStateFile+")")
 }
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// deleteTarget is a single resource selected for deletion
+type deleteTarget struct {
+	Org  string
+	Repo string
+	Env  string
+	Kind string
+	Name string
+}
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "A delete command for various resources",
+	Long: `A delete command for various resources. For example:
+		- Removing Deployment Environments
+		- Removing Secrets
+		- Removing Variables
+
+Resources are taken from the configuration file, narrowed down by --repo, --env, --name and --exclude.
+When --repo and --env (and --name for secrets and variables) each name a single resource without globs
+the config file is not needed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		typeFlag, _ := cmd.Flags().GetString("type")
+		org, _ := cmd.Flags().GetString("org")
+		yes, _ := cmd.Flags().GetBool("yes")
+		filter := filterFromFlags(cmd)
+
+		kind := ""
+		switch typeFlag {
+		case "deployment-env":
+			kind = "N/A"
+		case "secret", "secrets":
+			kind = "secret"
+		case "variable", "variables":
+			kind = "variable"
+		default:
+			fmt.Println("Error: --type flag is required. Options: deployment-env, secret, variable")
+			return
+		}
+
+		var targets []deleteTarget
+		// Freezes are declared in the config, which explicit targets may do without
+		var freezeConfig types.Config
+		if repo, env, name, ok := explicitTarget(filter, kind); ok {
+			if org == "" {
+				if config, err := loadConfig(); err == nil && len(config.Orgs) == 1 {
+					org = orgNames(config)[0]
+				}
+			}
+			if org == "" {
+				fmt.Println("Error: --org flag is required when no config file or several organizations are used")
+				return
+			}
+			targets = append(targets, deleteTarget{Org: org, Repo: repo, Env: env, Kind: kind, Name: name})
+			freezeConfig = loadFreezeConfig()
+		} else {
+			config, err := loadFilteredConfig(cmd)
+			if err != nil {
+				fmt.Printf("Error parsing config: %v\n", err)
+				return
+			}
+			freezeConfig = config
+			for _, orgName := range orgNames(config) {
+				if org != "" && orgName != org {
+					continue
+				}
+				targets = append(targets, configDeleteTargets(orgName, config.Orgs[orgName].Repos, kind)...)
+			}
+		}
+
+		var allowed []deleteTarget
+		for _, target := range targets {
+			if freezeAllows(cmd, freezeConfig, target.Org, target.Repo, target.Env) {
+				allowed = append(allowed, target)
+			}
+		}
+		targets = allowed
+
+		if len(targets) == 0 {
+			fmt.Println("Nothing to delete")
+			return
+		}
+
+		sort.Slice(targets, func(i, j int) bool {
+			return fmt.Sprint(targets[i]) < fmt.Sprint(targets[j])
+		})
+		fmt.Println("The following resources will be deleted:")
+		for _, target := range targets {
+			if target.Kind == "N/A" {
+				fmt.Printf("  - deployment environment %s/%s/%s\n", target.Org, target.Repo, target.Env)
+			} else {
+				fmt.Printf("  - %s %s in %s/%s/%s\n", target.Kind, target.Name, target.Org, target.Repo, target.Env)
+			}
+		}
+		if !yes && !confirm(fmt.Sprintf("Delete %d resource(s)?", len(targets))) {
+			fmt.Println("Aborted")
+			return
+		}
+
+		summary := make(map[summaryKey]string)
+		for _, target := range targets {
+			var status string
+			var err error
+			switch target.Kind {
+			case "N/A":
+				status, err = api.DeleteDeploymentEnv(target.Org, target.Repo, target.Env)
+			case "secret":
+				status, err = api.DeleteSecret(target.Org, target.Repo, target.Env, target.Name)
+			case "variable":
+				status, err = api.DeleteVariable(target.Org, target.Repo, target.Env, target.Name)
+			}
+			if err != nil {
+				fmt.Printf("Error deleting %s/%s/%s: %v\n", target.Repo, target.Env, target.Name, err)
+				status = "error"
+			}
+			summary[summaryKey{target.Org, target.Repo, target.Env, target.Kind, target.Name, "N/A"}] = status
+		}
+		printSummary(summary)
+	},
+}
+
+// explicitTarget returns the single resource named by the filter flags, so it can be deleted without a config file.
+// It only applies when --repo and --env (and --name for secrets and variables) each name exactly one item without globs.
+func explicitTarget(filter config.Filter, kind string) (repo, env, name string, ok bool) {
+	literal := func(values []string) bool {
+		return len(values) == 1 && !strings.ContainsAny(values[0], "*?[/")
+	}
+	if len(filter.Exclude) > 0 || !literal(filter.Repos) || !literal(filter.Envs) {
+		return "", "", "", false
+	}
+	if kind == "N/A" {
+		return filter.Repos[0], filter.Envs[0], "N/A", true
+	}
+	if !literal(filter.Names) {
+		return "", "", "", false
+	}
+	return filter.Repos[0], filter.Envs[0], filter.Names[0], true
+}
+
+// configDeleteTargets lists the resources of one organization's (already filtered) repos
+func configDeleteTargets(org string, repos map[string]map[string]types.DeploymentEnvOptions, kind string) []deleteTarget {
+	var targets []deleteTarget
+	for repoName, environments := range repos {
+		for envName, envOptions := range environments {
+			switch kind {
+			case "N/A":
+				targets = append(targets, deleteTarget{Org: org, Repo: repoName, Env: envName, Kind: kind, Name: "N/A"})
+			case "secret":
+				for secretName := range envOptions.Secrets {
+					targets = append(targets, deleteTarget{Org: org, Repo: repoName, Env: envName, Kind: kind, Name: secretName})
+				}
+			case "variable":
+				for variableName := range envOptions.Variables {
+					targets = append(targets, deleteTarget{Org: org, Repo: repoName, Env: envName, Kind: kind, Name: variableName})
+				}
+			}
+		}
+	}
+	return targets
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+	deleteCmd.Flags().StringP("type", "t", "", "Type of resource to delete. Options deployment-env, secret, variable")
+	deleteCmd.Flags().String("org", "", "Only delete resources in this organization (required without a config file)")
+	addFilterFlags(deleteCmd)
+	addFreezeFlags(deleteCmd)
+	deleteCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+}
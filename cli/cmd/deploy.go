@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/deploy"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+const defaultDeployWorkflow = "deploy.yml"
+
+// deployRequest describes a workflow dispatch deploying a ref to an environment
+type deployRequest struct {
+	Org      string
+	Repo     string
+	Env      string
+	Ref      string
+	Workflow string
+	Inputs   map[string]string
+	// CorrelationInput names a workflow input receiving a unique ID, which the workflow's run-name
+	// must contain, so that the run is found even among concurrent dispatches
+	CorrelationInput string
+}
+
+// deployCmd represents the deploy command
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Trigger a deployment workflow for an environment",
+	Long: `Trigger a deployment by dispatching a workflow (workflow_dispatch) on a branch or tag and follow the run.
+
+The environment is passed as the "environment" input, like the choice input of .github/workflows/action.yaml.
+The workflow is taken from --workflow, then deployWorkflow in the config, then ` + defaultDeployWorkflow + `.
+The run started is the new one on --ref by the token's user. When several deploys can be dispatched at
+once, pass --correlation-input with an input the workflow shows in its run-name, e.g.
+run-name: Deploy ${{ inputs.environment }} (${{ inputs.correlation_id }}).
+With --wait the command follows the run until it completes and exits with status 1 unless it succeeded.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		env, _ := cmd.Flags().GetString("env")
+		ref, _ := cmd.Flags().GetString("ref")
+		workflow, _ := cmd.Flags().GetString("workflow")
+		inputFlags, _ := cmd.Flags().GetStringArray("input")
+		envInput, _ := cmd.Flags().GetString("env-input")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		correlationInput, _ := cmd.Flags().GetString("correlation-input")
+		if repo == "" || env == "" || ref == "" {
+			fmt.Println("Error: --repo, --env and --ref flags are required")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		inputs, err := deployInputs(inputFlags, envInput, env)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Without a config file there are no freezes and the default workflow is used
+		cfg := loadFreezeConfig()
+		if !freezeAllows(cmd, cfg, org, repo, env) {
+			os.Exit(1)
+		}
+
+		request := deployRequest{Org: org, Repo: repo, Env: env, Ref: ref, Workflow: workflowFor(cfg, workflow, org, repo, env), Inputs: inputs, CorrelationInput: correlationInput}
+		run, err := dispatchDeploy(request)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if wait {
+			if run, err = waitForRun(org, repo, run, timeout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if run.Conclusion != "success" {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// workflowFor picks the workflow from the flag, the environment's deployWorkflow in cfg or the default
+func workflowFor(cfg types.Config, flag, org, repo, env string) string {
+	if flag != "" {
+		return flag
+	}
+	if envOptions, ok := cfg.Orgs[org].Repos[repo][env]; ok && envOptions.DeployWorkflow != "" {
+		return envOptions.DeployWorkflow
+	}
+	return defaultDeployWorkflow
+}
+
+// deployInputs builds the workflow inputs from --input flags, adding the environment under envInput
+func deployInputs(inputFlags []string, envInput, env string) (map[string]string, error) {
+	inputs := make(map[string]string)
+	for _, input := range inputFlags {
+		key, value, ok := strings.Cut(input, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("--input %q is not key=value", input)
+		}
+		inputs[key] = value
+	}
+	if _, ok := inputs[envInput]; envInput != "" && !ok {
+		inputs[envInput] = env
+	}
+	return inputs, nil
+}
+
+// dispatchDeploy dispatches the workflow and waits for the run it started to show up
+func dispatchDeploy(request deployRequest) (*api.WorkflowRun, error) {
+	// Runs already there are remembered so that the new one can be told apart
+	since := time.Now().Add(-time.Minute)
+	existing, err := api.FetchDispatchedRuns(request.Org, request.Repo, request.Workflow, since)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int]bool)
+	for _, run := range existing {
+		seen[run.ID] = true
+	}
+
+	inputs := request.Inputs
+	correlationID := ""
+	if request.CorrelationInput != "" {
+		correlationID = fmt.Sprintf("autogit-%d", time.Now().UnixNano())
+		inputs = maps.Clone(request.Inputs)
+		if inputs == nil {
+			inputs = make(map[string]string)
+		}
+		inputs[request.CorrelationInput] = correlationID
+	}
+	// Installation tokens have no user, their runs are then only matched on the ref
+	actor, err := api.CurrentUser(request.Org)
+	if err != nil {
+		actor = ""
+	}
+
+	fmt.Printf("Dispatching %s on %s for %s/%s/%s\n", request.Workflow, request.Ref, request.Org, request.Repo, request.Env)
+	if err := api.DispatchWorkflow(request.Org, request.Repo, request.Workflow, request.Ref, inputs); err != nil {
+		return nil, err
+	}
+
+	// GitHub does not return the run of a dispatch, it usually appears within a few seconds
+	for attempt := 0; attempt < 20; attempt++ {
+		time.Sleep(3 * time.Second)
+		runs, err := api.FetchDispatchedRuns(request.Org, request.Repo, request.Workflow, since)
+		if err != nil {
+			return nil, err
+		}
+		if run := deploy.DispatchedRun(runs, seen, request.Ref, actor, correlationID); run != nil {
+			fmt.Printf("Started run #%d: %s\n", run.RunNumber, run.HTMLURL)
+			return run, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow %s was dispatched but its run did not show up within a minute", request.Workflow)
+}
+
+// waitForRun polls a workflow run until it completes, printing every status change
+func waitForRun(org, repo string, run *api.WorkflowRun, timeout time.Duration) (*api.WorkflowRun, error) {
+	start := time.Now()
+	status := ""
+	for {
+		if run.Status != status {
+			status = run.Status
+			fmt.Printf("[%s] run #%d %s\n", time.Since(start).Round(time.Second), run.RunNumber, status)
+		}
+		if run.Status == "completed" {
+			fmt.Printf("Run #%d finished with conclusion %s: %s\n", run.RunNumber, run.Conclusion, run.HTMLURL)
+			return run, nil
+		}
+		if timeout > 0 && time.Since(start) > timeout {
+			return run, fmt.Errorf("run #%d did not complete within %s", run.RunNumber, timeout)
+		}
+		time.Sleep(10 * time.Second)
+
+		current, err := api.GetWorkflowRun(org, repo, run.ID)
+		if err != nil {
+			return run, err
+		}
+		run = current
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.Flags().String("org", "", "Organization of the repository (default is the only organization in the config)")
+	deployCmd.Flags().String("repo", "", "Repository to deploy")
+	deployCmd.Flags().String("env", "", "Deployment environment")
+	deployCmd.Flags().String("ref", "", "Branch or tag to deploy")
+	addDeployFlags(deployCmd)
+	addFreezeFlags(deployCmd)
+}
+
+// addDeployFlags adds the flags of the commands dispatching the deployment workflow
+func addDeployFlags(cmd *cobra.Command) {
+	cmd.Flags().String("workflow", "", "Workflow file name or ID (default is deployWorkflow from config or "+defaultDeployWorkflow+")")
+	cmd.Flags().StringArray("input", nil, "Workflow input as key=value, can be repeated")
+	cmd.Flags().String("env-input", "environment", "Workflow input receiving the environment name, empty to not send it")
+	cmd.Flags().Bool("wait", false, "Wait for the run to complete, printing its status")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "How long --wait waits for the run")
+	cmd.Flags().String("correlation-input", "", "Workflow input receiving a unique ID the workflow's run-name shows, to find its run among concurrent ones")
+}
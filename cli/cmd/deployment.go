@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// deploymentStates are the states a deployment status can be set to
+var deploymentStates = []string{"error", "failure", "inactive", "in_progress", "queued", "pending", "success"}
+
+// deploymentCmd groups the commands recording deployments made outside GitHub Actions
+var deploymentCmd = &cobra.Command{
+	Use:   "deployment",
+	Short: "Record deployments made outside GitHub Actions",
+	Long: `Record deployments made by other systems (Argo, scripts, ...) so that GitHub's Deployments view
+and the data read by fetch, promote and rollback stay accurate.`,
+}
+
+// deploymentCreateCmd represents the deployment create command
+var deploymentCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a deployment of a ref to an environment",
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		env, _ := cmd.Flags().GetString("env")
+		ref, _ := cmd.Flags().GetString("ref")
+		payload, _ := cmd.Flags().GetString("payload")
+		description, _ := cmd.Flags().GetString("description")
+		if repo == "" || env == "" || ref == "" {
+			fmt.Println("Error: --repo, --env and --ref flags are required")
+			return
+		}
+		if payload != "" && !json.Valid([]byte(payload)) {
+			fmt.Println("Error: --payload is not valid JSON")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		deployment, err := api.CreateDeployment(org, repo, ref, env, description, json.RawMessage(payload))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created deployment %d of %s (%s) to %s/%s/%s\n", deployment.ID, deployment.Ref, shortSHA(deployment.SHA), org, repo, env)
+	},
+}
+
+// deploymentStatusCmd represents the deployment status command
+var deploymentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Set the state of a deployment",
+	Long: `Set the state of a deployment. When it becomes success, GitHub sets older deployments to the same
+environment inactive, unless --keep-older is given. GitHub skips production deployments, so AutoGit marks
+older ones that are still success inactive itself.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		id, _ := cmd.Flags().GetInt("id")
+		keepOlder, _ := cmd.Flags().GetBool("keep-older")
+		var status types.DeploymentStatus
+		status.State, _ = cmd.Flags().GetString("state")
+		status.LogURL, _ = cmd.Flags().GetString("log-url")
+		status.EnvironmentURL, _ = cmd.Flags().GetString("environment-url")
+		status.Description, _ = cmd.Flags().GetString("description")
+		if keepOlder {
+			autoInactive := false
+			status.AutoInactive = &autoInactive
+		}
+		if repo == "" || id == 0 || status.State == "" {
+			fmt.Println("Error: --repo, --id and --state flags are required")
+			return
+		}
+		if !slices.Contains(deploymentStates, status.State) {
+			fmt.Printf("Error: --state must be one of %v\n", deploymentStates)
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		if _, err := api.CreateDeploymentStatus(org, repo, id, status); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Deployment %d of %s/%s is now %s\n", id, org, repo, status.State)
+
+		if status.State == "success" && !keepOlder {
+			if err := inactivateOlderDeployments(org, repo, id); err != nil {
+				fmt.Printf("Error marking older deployments inactive: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// inactivateOlderDeployments sets the older deployments to the same environment that are still success to inactive.
+// It only covers production deployments, as auto_inactive already takes care of the others.
+func inactivateOlderDeployments(org, repo string, deploymentID int) error {
+	deployment, err := api.GetDeployment(org, repo, deploymentID)
+	if err != nil {
+		return err
+	}
+	if !deployment.ProductionEnvironment {
+		return nil
+	}
+	deployments, err := api.FetchEnvironmentDeployments(org, repo, deployment.Environment)
+	if err != nil {
+		return err
+	}
+	for _, older := range deployments {
+		if older.ID >= deploymentID {
+			continue
+		}
+		state, err := api.LatestDeploymentState(org, repo, older.ID)
+		if err != nil {
+			return err
+		}
+		if state != "success" {
+			continue
+		}
+		if _, err := api.CreateDeploymentStatus(org, repo, older.ID, types.DeploymentStatus{State: "inactive", Description: fmt.Sprintf("Superseded by deployment %d", deploymentID)}); err != nil {
+			return err
+		}
+		fmt.Printf("Deployment %d of %s is now inactive\n", older.ID, older.Ref)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(deploymentCmd)
+	deploymentCmd.AddCommand(deploymentCreateCmd)
+	deploymentCmd.AddCommand(deploymentStatusCmd)
+	deploymentCmd.PersistentFlags().String("org", "", "Organization of the repository (default is the only organization in the config)")
+	deploymentCmd.PersistentFlags().String("repo", "", "Repository of the deployment")
+	deploymentCmd.PersistentFlags().String("description", "", "Short description of the deployment or status")
+	deploymentCreateCmd.Flags().String("env", "", "Deployment environment")
+	deploymentCreateCmd.Flags().String("ref", "", "Branch, tag or SHA that was deployed")
+	deploymentCreateCmd.Flags().String("payload", "", "Extra JSON data stored with the deployment")
+	deploymentStatusCmd.Flags().Int("id", 0, "Deployment ID")
+	deploymentStatusCmd.Flags().String("state", "", "New state. Options error, failure, inactive, in_progress, queued, pending, success")
+	deploymentStatusCmd.Flags().String("log-url", "", "URL of the deploy logs")
+	deploymentStatusCmd.Flags().String("environment-url", "", "URL of the deployed environment")
+	deploymentStatusCmd.Flags().Bool("keep-older", false, "Do not mark older successful deployments inactive")
+}
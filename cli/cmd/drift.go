@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/drift"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/spf13/cobra"
+)
+
+// driftCmd represents the drift command
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Detect drift between GitHub and the configuration file",
+	Long: `Compare the environments, variables and secrets in the configuration file with GitHub and report:
+		- Drifted items whose settings or values differ
+		- Missing items that are configured but do not exist
+		- Unmanaged items that exist but are not configured
+
+Exits with status 1 when drift is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		filter := filterFromFlags(cmd)
+		config, err := loadFilteredConfig(cmd)
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
+			os.Exit(1)
+		}
+		report, _ := cmd.Flags().GetString("report")
+		ignoreUnmanaged, _ := cmd.Flags().GetBool("ignore-unmanaged")
+
+		var results []drift.Result
+		failed := false
+		for _, orgName := range orgNames(config) {
+			for repoName, environments := range config.Orgs[orgName].Repos {
+				fmt.Printf("Checking repository: %s/%s\n", orgName, repoName)
+				repoResults, err := repoDrift(orgName, repoName, environments, filter)
+				if err != nil {
+					fmt.Printf("Error checking %s/%s: %v\n", orgName, repoName, err)
+					failed = true
+					continue
+				}
+				results = append(results, repoResults...)
+			}
+		}
+
+		sort.Slice(results, func(i, j int) bool {
+			a, b := results[i], results[j]
+			if a.Org != b.Org {
+				return a.Org < b.Org
+			}
+			if a.Repo != b.Repo {
+				return a.Repo < b.Repo
+			}
+			if a.Env != b.Env {
+				return a.Env < b.Env
+			}
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			return a.Name < b.Name
+		})
+
+		t := driftTable(results)
+		t.SetOutputMirror(os.Stdout)
+		t.SetStyle(table.StyleColoredBlackOnYellowWhite)
+		fmt.Printf("\n\n\n")
+		t.Render()
+
+		if report != "" {
+			markdown := fmt.Sprintf("# Drift report for %s\n\n%s\n", strings.Join(orgNames(config), ", "), driftTable(results).RenderMarkdown())
+			if err := os.WriteFile(report, []byte(markdown), 0644); err != nil {
+				fmt.Printf("Error writing report: %v\n", err)
+				failed = true
+			} else {
+				fmt.Printf("Report written to %s\n", report)
+			}
+		}
+
+		if failed || drift.HasDrift(results, ignoreUnmanaged) {
+			os.Exit(1)
+		}
+	},
+}
+
+// repoDrift compares every configured environment of a repository with GitHub. The environments are
+// already filtered, so what GitHub has is filtered the same way to not report the rest as unmanaged.
+func repoDrift(org, repo string, environments map[string]types.DeploymentEnvOptions, filter config.Filter) ([]drift.Result, error) {
+	actualEnvironments, err := api.ListEnvironments(org, repo)
+	if err != nil {
+		return nil, err
+	}
+	// Environment names are case-insensitive in GitHub
+	actualByName := make(map[string]types.Environment, len(actualEnvironments))
+	for _, environment := range actualEnvironments {
+		if !filter.MatchEnv(repo, environment.Name) {
+			continue
+		}
+		actualByName[strings.ToLower(environment.Name)] = environment
+	}
+
+	var results []drift.Result
+	for envName, envOptions := range environments {
+		environment, exists := actualByName[strings.ToLower(envName)]
+		delete(actualByName, strings.ToLower(envName))
+
+		var actualOptions types.DeploymentEnvOptions
+		if exists {
+			actualOptions = api.MapEnvironmentOptions(environment)
+			if len(envOptions.BranchPolicies) > 0 {
+				if actualOptions.BranchPolicies, err = api.ListBranchPolicies(org, repo, environment.Name); err != nil {
+					return nil, err
+				}
+			}
+		}
+		results = append(results, drift.CompareEnvironment(repo, envName, envOptions, actualOptions, exists)...)
+
+		if envOptions.CreateVariables {
+			var variables []types.Variable
+			if exists {
+				if variables, err = api.ListVariables(org, repo, environment.Name); err != nil {
+					return nil, err
+				}
+				variables = slices.DeleteFunc(variables, func(v types.Variable) bool { return !filter.MatchName(repo, envName, v.Name) })
+			}
+			results = append(results, drift.CompareVariables(repo, envName, envOptions.Variables, variables)...)
+		}
+		if envOptions.CreateSecrets {
+			var secrets []types.SecretMetadata
+			if exists {
+				if secrets, err = api.ListSecrets(org, repo, environment.Name); err != nil {
+					return nil, err
+				}
+				secrets = slices.DeleteFunc(secrets, func(s types.SecretMetadata) bool { return !filter.MatchName(repo, envName, s.Name) })
+			}
+			results = append(results, drift.CompareSecrets(repo, envName, envOptions.Secrets, secrets)...)
+		}
+	}
+
+	for _, environment := range actualByName {
+		results = append(results, drift.Result{Repo: repo, Env: environment.Name, Kind: "environment", Name: "N/A", Actual: "exists", Status: drift.Unmanaged})
+	}
+	for i := range results {
+		results[i].Org = org
+	}
+	return results, nil
+}
+
+// driftTable builds the drift results table shared by the terminal output and the Markdown report
+func driftTable(results []drift.Result) table.Writer {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Org", "Repo", "Environment", "Kind", "Name", "Expected", "Actual", "Status"})
+	for _, result := range results {
+		status := result.Status
+		switch status {
+		case drift.Unchanged:
+			status = fmt.Sprintf("%s 🆗", status)
+		case drift.Drifted:
+			status = fmt.Sprintf("%s 💣", status)
+		case drift.Missing:
+			status = fmt.Sprintf("%s ❌", status)
+		case drift.Unmanaged:
+			status = fmt.Sprintf("%s ❓", status)
+		}
+		t.AppendRow([]interface{}{result.Org, result.Repo, result.Env, result.Kind, result.Name, result.Expected, result.Actual, status})
+	}
+	return t
+}
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+	addFilterFlags(driftCmd)
+	driftCmd.Flags().String("report", "", "Also write the results as a Markdown report to this file")
+	driftCmd.Flags().Bool("ignore-unmanaged", false, "Do not exit with status 1 for unmanaged items only")
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/envfile"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// envCmd groups the commands working on a single deployment environment
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Work with the variables and secrets of a single deployment environment",
+}
+
+// envLoadCmd represents the env load command
+var envLoadCmd = &cobra.Command{
+	Use:   "load",
+	Short: "Load variables and secrets from dotenv or JSON files into an environment",
+	Long: `Load variables and secrets from files into a deployment environment. Files ending in .json
+(or starting with '{') are read as flat JSON objects, anything else as dotenv:
+		- KEY=value lines, with an optional 'export ' prefix
+		- # comments and blank lines
+		- 'single quoted' and "double quoted" values, which may span several lines
+
+With --save the values are also written into the config file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		env, _ := cmd.Flags().GetString("env")
+		variablesFile, _ := cmd.Flags().GetString("variables")
+		secretsFile, _ := cmd.Flags().GetString("secrets")
+		save, _ := cmd.Flags().GetBool("save")
+		if repo == "" || env == "" {
+			fmt.Println("Error: --repo and --env flags are required")
+			return
+		}
+		if variablesFile == "" && secretsFile == "" {
+			fmt.Println("Error: at least one of --variables and --secrets is required")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		var variables, secrets map[string]string
+		if variablesFile != "" {
+			if variables, err = envfile.Load(variablesFile); err != nil {
+				fmt.Printf("Error reading variables: %v\n", err)
+				return
+			}
+		}
+		if secretsFile != "" {
+			if secrets, err = envfile.Load(secretsFile); err != nil {
+				fmt.Printf("Error reading secrets: %v\n", err)
+				return
+			}
+		}
+
+		cfg := loadFreezeConfig()
+		if !freezeAllows(cmd, cfg, org, repo, env) {
+			return
+		}
+
+		summary := make(map[summaryKey]string)
+		run := &createRun{summary: summary}
+		// The create handlers record every secret as an error when the public key cannot be fetched,
+		// so the summary and --save still cover the values that were applied
+		values := types.DeploymentEnvOptions{Variables: variables, Secrets: secrets}
+		if len(variables) > 0 {
+			createVariables(run, org, repo, env, values)
+		}
+		createSecrets(run, org, repo, env, values)
+
+		if save {
+			if written, err := config.SetEnvironmentValues(configPath(), org, repo, env, variables, secrets); err != nil {
+				fmt.Printf("Error saving values to the config: %v\n", err)
+			} else {
+				fmt.Printf("Values written to %s\n", written)
+			}
+		}
+
+		printSummary(summary)
+	},
+}
+
+// envExportCmd represents the env export command
+var envExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the variables visible to an environment",
+	Long: `Export the variables a workflow job sees in a deployment environment: the environment variables,
+the repository variables and the organization variables shared with the repository. As in GitHub Actions
+environment variables win over repository variables, which win over organization variables.
+Secrets are never exported as GitHub does not return their values.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		env, _ := cmd.Flags().GetString("env")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		if repo == "" || env == "" {
+			fmt.Println("Error: --repo and --env flags are required")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		orgVariables, err := api.ListOrgVariables(org, repo)
+		if err != nil {
+			fmt.Printf("Error listing organization variables for %s/%s: %v\n", org, repo, err)
+			return
+		}
+		repoVariables, err := api.ListRepoVariables(org, repo)
+		if err != nil {
+			fmt.Printf("Error listing repository variables for %s/%s: %v\n", org, repo, err)
+			return
+		}
+		envVariables, err := api.ListVariables(org, repo, env)
+		if err != nil {
+			fmt.Printf("Error listing variables for %s/%s/%s: %v\n", org, repo, env, err)
+			return
+		}
+
+		data, err := envfile.Format(envfile.Merge(variableValues(orgVariables), variableValues(repoVariables), variableValues(envVariables)), format)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if output == "" {
+			fmt.Print(string(data))
+			return
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			fmt.Printf("Error writing to file %s: %v\n", output, err)
+			return
+		}
+		fmt.Printf("Output written to %s\n", output)
+	},
+}
+
+// variableValues turns a variable listing into a name to value map
+func variableValues(variables []types.Variable) map[string]string {
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		values[variable.Name] = variable.Value
+	}
+	return values
+}
+
+// commandOrg returns the --org flag, or the only organization of the config when it is not set
+func commandOrg(cmd *cobra.Command) (string, error) {
+	if org, _ := cmd.Flags().GetString("org"); org != "" {
+		return org, nil
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("--org flag is required without a config file: %w", err)
+	}
+	if len(cfg.Orgs) != 1 {
+		return "", fmt.Errorf("--org flag is required when the config has %d organizations", len(cfg.Orgs))
+	}
+	return orgNames(cfg)[0], nil
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envLoadCmd)
+	envCmd.AddCommand(envExportCmd)
+	envCmd.PersistentFlags().String("org", "", "Organization of the repository (default is the only organization in the config)")
+	envCmd.PersistentFlags().String("repo", "", "Repository of the environment")
+	envCmd.PersistentFlags().String("env", "", "Deployment environment")
+	envLoadCmd.Flags().String("variables", "", "Dotenv or JSON file with variables")
+	envLoadCmd.Flags().String("secrets", "", "Dotenv or JSON file with secrets")
+	envExportCmd.Flags().String("format", "dotenv", "Output format. Options dotenv, json, yaml")
+	envExportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+	addFreezeFlags(envLoadCmd)
+	envLoadCmd.Flags().Bool("save", false, "Also write the values into the config file given with --config")
+}
@@ -6,7 +6,6 @@ import (
 	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
 	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 var outputFile string
@@ -14,6 +13,7 @@ var outputFile string
 func init() {
 	fetchCmd.Flags().StringVarP(&outputFile, "output", "o", "output.yaml", "Path to the output YAML file")
 	rootCmd.AddCommand(fetchCmd)
+	addFilterFlags(fetchCmd)
 }
 
 var fetchCmd = &cobra.Command{
@@ -21,39 +21,48 @@ var fetchCmd = &cobra.Command{
 	Short: "Fetch deployment and release data",
 	Long:  "Fetch deployment, release, and workflow data from GitHub repositories specified in the configuration file.",
 	Run: func(cmd *cobra.Command, args []string) {
-		var config types.Config
-
-		if err := viper.Unmarshal(&config); err != nil {
+		config, err := loadFilteredConfig(cmd)
+		if err != nil {
 			fmt.Printf("Error parsing config: %v\n", err)
 			return
 		}
 
-		output := types.OutputData{
-			Organization: config.Org,
-			Repositories: make(map[string]map[string]types.EnvData),
-		}
+		var outputs []types.OutputData
+		for _, orgName := range orgNames(config) {
+			output := types.OutputData{
+				Organization: orgName,
+				Repositories: make(map[string]map[string]types.EnvData),
+			}
 
-		fmt.Printf("Organization: %s\n\n", config.Org)
-		for repo, environments := range config.Repos {
-			fmt.Printf("Fetching deployments for repo: %s/%s\n", config.Org, repo)
-			repoData := make(map[string]types.EnvData)
+			fmt.Printf("Organization: %s\n\n", orgName)
+			for repo, environments := range config.Orgs[orgName].Repos {
+				fmt.Printf("Fetching deployments for repo: %s/%s\n", orgName, repo)
+				repoData := make(map[string]types.EnvData)
 
-			deployments, err := api.FetchDeployments(config.Org, repo)
-			if err != nil {
-				fmt.Printf("Error fetching deployments for repo %s: %v\n", repo, err)
-				continue
-			}
+				deployments, err := api.FetchDeployments(orgName, repo)
+				if err != nil {
+					fmt.Printf("Error fetching deployments for repo %s: %v\n", repo, err)
+					continue
+				}
 
-			for envName, envOptions := range environments {
-				if envOptions.FetchReleases {
-					fmt.Printf("  Processing environment: %s\n", envName)
-					envData := api.MapEnvironmentData(deployments, envName, envOptions)
-					repoData[envName] = envData
-				} else {
-					fmt.Printf("  Skipping '%s' (fetchRelease is false\n", envName)
+				for envName, envOptions := range environments {
+					if envOptions.FetchReleases {
+						fmt.Printf("  Processing environment: %s\n", envName)
+						envData := api.MapEnvironmentData(deployments, envName, envOptions)
+						repoData[envName] = envData
+					} else {
+						fmt.Printf("  Skipping '%s' (fetchRelease is false\n", envName)
+					}
 				}
+				output.Repositories[repo] = repoData
 			}
-			output.Repositories[repo] = repoData
+			outputs = append(outputs, output)
+		}
+
+		// A single organization keeps the original output layout, several are written as a list
+		var output interface{} = outputs
+		if len(outputs) == 1 {
+			output = outputs[0]
 		}
 		if err := api.WriteOutput(output, outputFile); err != nil {
 			fmt.Printf("Error writing output: %v\n", err)
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// addFilterFlags adds the --repo, --env, --name and --exclude flags shared by the commands working on the config
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("repo", nil, "Only process repositories matching this glob (org/repo patterns match the org too), can be repeated")
+	cmd.Flags().StringSlice("env", nil, "Only process environments matching this glob, can be repeated")
+	cmd.Flags().StringSlice("name", nil, "Only process secrets and variables matching this glob, can be repeated")
+	cmd.Flags().StringSlice("exclude", nil, "Skip anything whose repo, repo/env or repo/env/name matches this glob, can be repeated")
+}
+
+// filterFromFlags reads the filter flags added by addFilterFlags
+func filterFromFlags(cmd *cobra.Command) config.Filter {
+	var filter config.Filter
+	filter.Repos, _ = cmd.Flags().GetStringSlice("repo")
+	filter.Envs, _ = cmd.Flags().GetStringSlice("env")
+	filter.Names, _ = cmd.Flags().GetStringSlice("name")
+	filter.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	return filter
+}
+
+// loadFilteredConfig loads the config and narrows it down with the filter flags
+func loadFilteredConfig(cmd *cobra.Command) (types.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return cfg, err
+	}
+	return filterFromFlags(cmd).Apply(cfg), nil
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/freeze"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/spf13/cobra"
+)
+
+// freezeCmd groups the commands about change freezes
+var freezeCmd = &cobra.Command{
+	Use:   "freeze",
+	Short: "Show the change freezes declared in the config",
+}
+
+// freezeStatusCmd represents the freeze status command
+var freezeStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show active and upcoming change freeze windows",
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
+			return
+		}
+		if len(cfg.Freezes) == 0 {
+			fmt.Println("No freezes are declared in the config")
+			return
+		}
+
+		now := time.Now()
+		windows := freeze.Windows(cfg.Freezes, now, now.AddDate(0, 0, days))
+		if len(windows) == 0 {
+			fmt.Printf("No freeze windows in the next %d days\n", days)
+			return
+		}
+
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Freeze", "Status", "Start", "End", "Orgs", "Repos", "Environments"})
+		for _, window := range windows {
+			status := "Upcoming ⏳"
+			if !window.Start.After(now) {
+				status = "Active 🧊"
+			}
+			t.AppendRow([]interface{}{window.Freeze.Name, status, window.Start.Format("Mon 2006-01-02 15:04 MST"), window.End.Format("Mon 2006-01-02 15:04 MST"),
+				freezeScope(window.Freeze.Orgs), freezeScope(window.Freeze.Repos), freezeScope(window.Freeze.Environments)})
+		}
+		t.SetStyle(table.StyleColoredBlackOnYellowWhite)
+		t.Render()
+	},
+}
+
+func freezeScope(patterns []string) string {
+	if len(patterns) == 0 {
+		return "*"
+	}
+	return strings.Join(patterns, ", ")
+}
+
+// addFreezeFlags adds the flags overriding change freezes to a command that deploys or changes environments
+func addFreezeFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("override-freeze", false, "Go ahead during a change freeze, requires --reason")
+	cmd.Flags().String("reason", "", "Why the change freeze is overridden, printed in an audit line")
+}
+
+// loadFreezeConfig loads the config of a command that works without one, for its change freezes.
+// Without a config file there are no freezes, but a config that fails to load, e.g. on an unset
+// environment variable, would silently skip them, so the command stops with status 1 instead.
+func loadFreezeConfig() types.Config {
+	path := configPath()
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && !strings.ContainsAny(path, "*?[") {
+		return types.Config{}
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Printf("Error: change freezes cannot be checked as the config does not load: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// freezeAllows reports whether an environment may be changed now. During a freeze it prints why not,
+// or an audit line when the freeze is overridden with a reason.
+func freezeAllows(cmd *cobra.Command, cfg types.Config, org, repo, env string) bool {
+	active := freeze.Active(cfg.Freezes, org, repo, env, time.Now())
+	if len(active) == 0 {
+		return true
+	}
+	var names []string
+	for _, window := range active {
+		names = append(names, fmt.Sprintf("%s (until %s)", window.Freeze.Name, window.End.Format("Mon 2006-01-02 15:04 MST")))
+	}
+
+	override, _ := cmd.Flags().GetBool("override-freeze")
+	reason, _ := cmd.Flags().GetString("reason")
+	switch {
+	case override && strings.TrimSpace(reason) != "":
+		fmt.Printf("AUDIT %s freeze override %s/%s/%s of %s: %s\n", time.Now().UTC().Format(time.RFC3339), org, repo, env, strings.Join(names, ", "), reason)
+		return true
+	case override:
+		fmt.Printf("Blocked: --override-freeze needs a --reason for %s/%s/%s\n", org, repo, env)
+	default:
+		fmt.Printf("Blocked: %s/%s/%s is frozen by %s. Use --override-freeze --reason to go ahead\n", org, repo, env, strings.Join(names, ", "))
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(freezeCmd)
+	freezeCmd.AddCommand(freezeStatusCmd)
+	freezeStatusCmd.Flags().Int("days", 14, "How many days ahead to show upcoming windows")
+}
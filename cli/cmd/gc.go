@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/gc"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/preview"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// gcCmd groups the clean-up commands
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up deployments and environments that are no longer needed",
+}
+
+// gcDeploymentsCmd represents the gc deployments command
+var gcDeploymentsCmd = &cobra.Command{
+	Use:   "deployments",
+	Short: "Delete deployments of environments no longer in the config or past a retention age",
+	Long: `Delete the deployments of the configured repositories (narrowed down by --repo, --env and --exclude) that are:
+		- for environments that are not in the config, e.g. ones removed from it
+		- older than --retention-days, always keeping the newest deployment of every configured environment
+
+The preview environments of the previews config are left to preview gc.
+
+Deployments are marked inactive before being deleted, as GitHub only deletes inactive deployments.
+With --delete-envs-after-days environments missing from the config without deployments for that many
+days are deleted too. Use --dry-run to only list what would be deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		retentionDays, _ := cmd.Flags().GetInt("retention-days")
+		idleDays, _ := cmd.Flags().GetInt("delete-envs-after-days")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		filter := filterFromFlags(cmd)
+
+		// The whole config is needed to tell which environments are configured, the filter only narrows the scope
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
+			return
+		}
+
+		type repoPlan struct {
+			Org          string
+			Repo         string
+			Deployments  []gc.DeploymentCandidate
+			Environments []gc.EnvironmentCandidate
+		}
+		var plans []repoPlan
+		total := 0
+		for _, orgName := range orgNames(cfg) {
+			for _, repoName := range slices.Sorted(maps.Keys(cfg.Orgs[orgName].Repos)) {
+				if !filter.MatchRepo(orgName, repoName) {
+					continue
+				}
+				policy := gc.Policy{
+					Configured:       make(map[string]bool),
+					Retention:        time.Duration(retentionDays) * 24 * time.Hour,
+					IdleEnvironments: time.Duration(idleDays) * 24 * time.Hour,
+					Now:              time.Now(),
+				}
+				// Preview environments of open pull requests have no config entry, preview gc removes them once closed
+				if previews := cfg.Orgs[orgName].Previews; previews != nil {
+					policy.Ignored = func(env string) bool {
+						_, ok := preview.PullNumber(*previews, env)
+						return ok
+					}
+				}
+				for envName := range cfg.Orgs[orgName].Repos[repoName] {
+					policy.Configured[envName] = true
+				}
+
+				fmt.Printf("Fetching deployments for %s/%s\n", orgName, repoName)
+				deployments, err := api.FetchDeployments(orgName, repoName)
+				if err != nil {
+					fmt.Printf("Error fetching deployments for %s/%s: %v\n", orgName, repoName, err)
+					continue
+				}
+				var scoped []types.Deployment
+				for _, deployment := range deployments {
+					if filter.MatchEnv(repoName, deployment.Environment) {
+						scoped = append(scoped, deployment)
+					}
+				}
+				plan := repoPlan{Org: orgName, Repo: repoName, Deployments: policy.Deployments(scoped)}
+
+				if idleDays > 0 {
+					environments, err := api.ListEnvironments(orgName, repoName)
+					if err != nil {
+						fmt.Printf("Error listing environments for %s/%s: %v\n", orgName, repoName, err)
+					}
+					var scopedEnvs []types.Environment
+					for _, environment := range environments {
+						if filter.MatchEnv(repoName, environment.Name) {
+							scopedEnvs = append(scopedEnvs, environment)
+						}
+					}
+					plan.Environments = policy.Environments(scopedEnvs, deployments)
+				}
+
+				// Environments in a change freeze are left alone
+				allowed := make(map[string]bool)
+				allows := func(env string) bool {
+					if _, ok := allowed[env]; !ok {
+						allowed[env] = freezeAllows(cmd, cfg, orgName, repoName, env)
+					}
+					return allowed[env]
+				}
+				plan.Deployments = slices.DeleteFunc(plan.Deployments, func(c gc.DeploymentCandidate) bool { return !allows(c.Deployment.Environment) })
+				plan.Environments = slices.DeleteFunc(plan.Environments, func(c gc.EnvironmentCandidate) bool { return !allows(c.Environment.Name) })
+				total += len(plan.Deployments) + len(plan.Environments)
+				plans = append(plans, plan)
+			}
+		}
+
+		// One summary row per environment and reason, as there can be thousands of deployments
+		summary := make(map[summaryKey]string)
+		counts := make(map[string]int)
+		countKey := func(org, repo, env, reason string) string {
+			return fmt.Sprintf("%s/%s/%s/%s", org, repo, env, reason)
+		}
+		for _, plan := range plans {
+			for _, candidate := range plan.Deployments {
+				counts[countKey(plan.Org, plan.Repo, candidate.Deployment.Environment, candidate.Reason)]++
+			}
+		}
+		if total == 0 {
+			fmt.Println("Nothing to clean up")
+			return
+		}
+		if dryRun {
+			for _, plan := range plans {
+				for _, candidate := range plan.Deployments {
+					key := countKey(plan.Org, plan.Repo, candidate.Deployment.Environment, candidate.Reason)
+					summary[summaryKey{plan.Org, plan.Repo, candidate.Deployment.Environment, "deployments", strconv.Itoa(counts[key]), candidate.Reason}] = "Would delete"
+				}
+				for _, candidate := range plan.Environments {
+					summary[summaryKey{plan.Org, plan.Repo, candidate.Environment.Name, "N/A", "N/A", candidate.Reason}] = "Would delete"
+				}
+			}
+			printSummary(summary)
+			return
+		}
+		if !yes && !confirm(fmt.Sprintf("Delete %d deployment(s) and environment(s)?", total)) {
+			fmt.Println("Aborted")
+			return
+		}
+
+		for _, plan := range plans {
+			failed := make(map[string]bool)
+			for _, candidate := range plan.Deployments {
+				deployment := candidate.Deployment
+				key := countKey(plan.Org, plan.Repo, deployment.Environment, candidate.Reason)
+				if err := removeDeployment(plan.Org, plan.Repo, deployment.ID, "Cleaned up by autogit gc"); err != nil {
+					fmt.Printf("Error deleting deployment %d of %s/%s: %v\n", deployment.ID, plan.Repo, deployment.Environment, err)
+					failed[key] = true
+				}
+				status := "Deleted"
+				if failed[key] {
+					status = "error"
+				}
+				summary[summaryKey{plan.Org, plan.Repo, deployment.Environment, "deployments", strconv.Itoa(counts[key]), candidate.Reason}] = status
+			}
+			for _, candidate := range plan.Environments {
+				status, err := api.DeleteDeploymentEnv(plan.Org, plan.Repo, candidate.Environment.Name)
+				if err != nil {
+					fmt.Printf("Error deleting environment %s of %s: %v\n", candidate.Environment.Name, plan.Repo, err)
+					status = "error"
+				}
+				summary[summaryKey{plan.Org, plan.Repo, candidate.Environment.Name, "N/A", "N/A", candidate.Reason}] = status
+			}
+		}
+		printSummary(summary)
+	},
+}
+
+// removeDeployment marks a deployment inactive, as GitHub only deletes inactive deployments, then deletes it
+func removeDeployment(org, repo string, deploymentID int, description string) error {
+	if _, err := api.CreateDeploymentStatus(org, repo, deploymentID, types.DeploymentStatus{State: "inactive", Description: description}); err != nil {
+		return err
+	}
+	return api.DeleteDeployment(org, repo, deploymentID)
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.AddCommand(gcDeploymentsCmd)
+	gcDeploymentsCmd.Flags().StringSlice("repo", nil, "Only clean up repositories matching this glob (org/repo patterns match the org too), can be repeated")
+	gcDeploymentsCmd.Flags().StringSlice("env", nil, "Only clean up environments matching this glob, can be repeated")
+	gcDeploymentsCmd.Flags().StringSlice("exclude", nil, "Skip anything whose repo or repo/env matches this glob, can be repeated")
+	gcDeploymentsCmd.Flags().Int("retention-days", 0, "Also delete deployments of configured environments older than this many days (0 keeps them)")
+	gcDeploymentsCmd.Flags().Int("delete-envs-after-days", 0, "Also delete environments missing from the config without deployments for this many days (0 keeps them)")
+	gcDeploymentsCmd.Flags().Bool("dry-run", false, "Only list what would be deleted")
+	gcDeploymentsCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	addFreezeFlags(gcDeploymentsCmd)
+}
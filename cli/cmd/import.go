@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import existing GitHub environments into a config file",
+	Long: `Import existing GitHub state into a config file. For every repository it reads:
+		- Deployment Environments with their protection rules and branch policies
+		- Environment Variables with their values
+		- Environment Secret names (values are left as placeholders and createSecrets is false)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		org, _ := cmd.Flags().GetString("org")
+		repos, _ := cmd.Flags().GetStringSlice("repo")
+		output, _ := cmd.Flags().GetString("output")
+		if org == "" {
+			fmt.Println("Error: --org flag is required")
+			return
+		}
+
+		if len(repos) == 0 {
+			fmt.Printf("Listing repositories for %s\n", org)
+			orgRepos, err := api.ListOrgRepos(org)
+			if err != nil {
+				fmt.Printf("Error listing repositories for %s: %v\n", org, err)
+				return
+			}
+			for _, repo := range orgRepos {
+				if !repo.Archived {
+					repos = append(repos, repo.Name)
+				}
+			}
+		}
+
+		config := types.Config{
+			Org:   org,
+			Repos: make(map[string]map[string]types.DeploymentEnvOptions),
+		}
+		for _, repo := range repos {
+			fmt.Printf("\nImporting repository: %s/%s\n", org, repo)
+			environments, err := importRepo(org, repo)
+			if err != nil {
+				fmt.Printf("Error importing %s/%s: %v\n", org, repo, err)
+				continue
+			}
+			if len(environments) == 0 {
+				fmt.Printf("  Skipping %s as it has no deployment environments\n", repo)
+				continue
+			}
+			config.Repos[repo] = environments
+		}
+
+		if err := api.WriteOutput(config, output); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+		}
+	},
+}
+
+// importRepo reads every environment of a repository with its variables and secret names
+func importRepo(org, repo string) (map[string]types.DeploymentEnvOptions, error) {
+	environments, err := api.ListEnvironments(org, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]types.DeploymentEnvOptions)
+	for _, environment := range environments {
+		fmt.Printf("  Importing environment: %s\n", environment.Name)
+		envOptions := api.MapEnvironmentOptions(environment)
+
+		if envOptions.DeploymentBranchPolicy != nil && envOptions.DeploymentBranchPolicy.CustomBranchPolicies {
+			policies, err := api.ListBranchPolicies(org, repo, environment.Name)
+			if err != nil {
+				return nil, err
+			}
+			envOptions.BranchPolicies = policies
+		}
+
+		variables, err := api.ListVariables(org, repo, environment.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(variables) > 0 {
+			envOptions.CreateVariables = true
+			envOptions.Variables = make(types.Values)
+			for _, variable := range variables {
+				envOptions.Variables[variable.Name] = variable.Value
+			}
+		}
+
+		secrets, err := api.ListSecrets(org, repo, environment.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(secrets) > 0 {
+			// Secret values are never returned, so createSecrets stays false until the placeholders are filled in
+			envOptions.Secrets = make(types.Values)
+			for _, secret := range secrets {
+				envOptions.Secrets[secret.Name] = types.SecretPlaceholder
+			}
+		}
+
+		result[environment.Name] = envOptions
+	}
+	return result, nil
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().String("org", "", "Organization to import")
+	importCmd.Flags().StringSlice("repo", nil, "Repository to import, can be repeated (default is every unarchived repository in the org)")
+	importCmd.Flags().StringP("output", "o", "imported-config.yaml", "Path to the generated config file")
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/preview"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// previewCmd groups the pull request preview environment commands
+var previewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Create and remove preview environments for pull requests",
+	Long: `Create and remove an environment per pull request, e.g. pr-123, from the previews section of the config:
+
+	previews:
+	  prefix: pr-             # default
+	  repos: ["web-*"]        # repositories allowed previews, default all
+	  environment:
+	    extends: preview
+	    variables:
+	      APP_URL: https://${{ env.name }}.preview.example.com
+
+The environment is resolved against defaults and environmentTemplates like any other, and only the pull
+request's head branch may deploy to it.`,
+}
+
+// previewUpCmd represents the preview up command
+var previewUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Create or update the preview environment of a pull request",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, org, previews, err := previewConfig(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		repo, _ := cmd.Flags().GetString("repo")
+		number, _ := cmd.Flags().GetInt("pr")
+		if repo == "" || number <= 0 {
+			fmt.Println("Error: --repo and --pr are required")
+			return
+		}
+		if !preview.AllowsRepo(previews, repo) {
+			fmt.Printf("Error: previews are not enabled for %s/%s\n", org, repo)
+			return
+		}
+
+		pull, err := api.GetPullRequest(org, repo, number)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if pull.State != "open" {
+			fmt.Printf("Error: pull request #%d of %s/%s is %s\n", number, org, repo, pull.State)
+			return
+		}
+
+		env := preview.EnvironmentName(previews, number)
+		envOptions := preview.Environment(previews, pull.Head.Ref)
+		if err := config.InterpolateEnvironment(org, repo, env, &envOptions); err != nil {
+			fmt.Printf("Error interpolating values: %v\n", err)
+			return
+		}
+
+		run := &createRun{
+			secretState: loadSecretState(cmd, cfg),
+			summary:     make(map[summaryKey]string),
+		}
+		if !freezeAllows(cmd, cfg, org, repo, env) {
+			run.record(org, repo, env, "N/A", "N/A", "N/A", "Frozen")
+			printSummary(run.summary)
+			return
+		}
+		fmt.Printf("Preview environment %s of %s/%s for #%d (%s)\n", env, org, repo, number, pull.Head.Ref)
+		handlers, _ := selectCreateHandlers("ALL", config.Filter{})
+		for _, handler := range handlers {
+			if handler.enabled(envOptions) {
+				handler.create(run, org, repo, env, envOptions)
+			}
+		}
+		if run.secretState != nil {
+			if err := run.secretState.Save(); err != nil {
+				fmt.Printf("Error saving secret state: %v\n", err)
+			}
+		}
+		printSummary(run.summary)
+	},
+}
+
+// previewDownCmd represents the preview down command
+var previewDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Delete the preview environment of a pull request and its deployments",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, org, previews, err := previewConfig(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		repo, _ := cmd.Flags().GetString("repo")
+		number, _ := cmd.Flags().GetInt("pr")
+		if repo == "" || number <= 0 {
+			fmt.Println("Error: --repo and --pr are required")
+			return
+		}
+
+		env := preview.EnvironmentName(previews, number)
+		summary := make(map[summaryKey]string)
+		if !freezeAllows(cmd, cfg, org, repo, env) {
+			summary[summaryKey{org, repo, env, "N/A", "N/A", "N/A"}] = "Frozen"
+		} else {
+			removePreview(org, repo, env, summary)
+		}
+		printSummary(summary)
+	},
+}
+
+// previewGCCmd represents the preview gc command
+var previewGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete the preview environments of closed pull requests",
+	Long: `Delete the preview environments, and their deployments, of closed or merged pull requests in the
+configured repositories that previews are enabled for, or in the repositories given with --repo.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, org, previews, err := previewConfig(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+		repos, _ := cmd.Flags().GetStringSlice("repo")
+		if len(repos) == 0 {
+			for _, repo := range slices.Sorted(maps.Keys(cfg.Orgs[org].Repos)) {
+				if preview.AllowsRepo(previews, repo) {
+					repos = append(repos, repo)
+				}
+			}
+		}
+
+		type stalePreview struct {
+			Repo string
+			Env  string
+		}
+		var stale []stalePreview
+		for _, repo := range repos {
+			environments, err := api.ListEnvironments(org, repo)
+			if err != nil {
+				fmt.Printf("Error listing environments for %s/%s: %v\n", org, repo, err)
+				continue
+			}
+			for _, environment := range environments {
+				number, ok := preview.PullNumber(previews, environment.Name)
+				if !ok {
+					continue
+				}
+				pull, err := api.GetPullRequest(org, repo, number)
+				if err != nil {
+					fmt.Printf("Error checking %s/%s/%s: %v\n", org, repo, environment.Name, err)
+					continue
+				}
+				if pull.State == "closed" {
+					stale = append(stale, stalePreview{Repo: repo, Env: environment.Name})
+				}
+			}
+		}
+
+		if len(stale) == 0 {
+			fmt.Println("No preview environments of closed pull requests")
+			return
+		}
+		summary := make(map[summaryKey]string)
+		if dryRun {
+			for _, s := range stale {
+				summary[summaryKey{org, s.Repo, s.Env, "N/A", "N/A", "closed"}] = "Would delete"
+			}
+			printSummary(summary)
+			return
+		}
+		if !yes && !confirm(fmt.Sprintf("Delete %d preview environment(s)?", len(stale))) {
+			fmt.Println("Aborted")
+			return
+		}
+		for _, s := range stale {
+			if !freezeAllows(cmd, cfg, org, s.Repo, s.Env) {
+				summary[summaryKey{org, s.Repo, s.Env, "N/A", "N/A", "N/A"}] = "Frozen"
+				continue
+			}
+			removePreview(org, s.Repo, s.Env, summary)
+		}
+		printSummary(summary)
+	},
+}
+
+// previewConfig loads the config and returns the organization of the command and its previews
+func previewConfig(cmd *cobra.Command) (types.Config, string, types.Previews, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return cfg, "", types.Previews{}, fmt.Errorf("failed to parse config: %w", err)
+	}
+	org, err := commandOrg(cmd)
+	if err != nil {
+		return cfg, "", types.Previews{}, err
+	}
+	previews := cfg.Orgs[org].Previews
+	if previews == nil {
+		return cfg, org, types.Previews{}, fmt.Errorf("no previews are configured for %s", org)
+	}
+	return cfg, org, *previews, nil
+}
+
+// removePreview deletes the deployments of a preview environment, then the environment itself
+func removePreview(org, repo, env string, summary map[summaryKey]string) {
+	deployments, err := api.FetchEnvironmentDeployments(org, repo, env)
+	if err != nil {
+		fmt.Printf("Error fetching deployments of %s/%s/%s: %v\n", org, repo, env, err)
+		summary[summaryKey{org, repo, env, "N/A", "N/A", "N/A"}] = "error"
+		return
+	}
+	deleted := 0
+	for _, deployment := range deployments {
+		if err := removeDeployment(org, repo, deployment.ID, "Preview environment removed by autogit"); err != nil {
+			fmt.Printf("Error deleting deployment %d of %s/%s: %v\n", deployment.ID, repo, env, err)
+			continue
+		}
+		deleted++
+	}
+	if len(deployments) > 0 {
+		status := "Deleted"
+		if deleted < len(deployments) {
+			status = "error"
+		}
+		summary[summaryKey{org, repo, env, "deployments", strconv.Itoa(deleted), "N/A"}] = status
+	}
+
+	status, err := api.DeleteDeploymentEnv(org, repo, env)
+	if err != nil {
+		fmt.Printf("Error deleting environment %s of %s: %v\n", env, repo, err)
+		status = "error"
+	}
+	summary[summaryKey{org, repo, env, "N/A", "N/A", "N/A"}] = status
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+	previewCmd.AddCommand(previewUpCmd)
+	previewCmd.AddCommand(previewDownCmd)
+	previewCmd.AddCommand(previewGCCmd)
+	previewCmd.PersistentFlags().String("org", "", "Organization of the repository (default is the only organization in the config)")
+	for _, cmd := range []*cobra.Command{previewUpCmd, previewDownCmd} {
+		cmd.Flags().String("repo", "", "Repository of the pull request")
+		cmd.Flags().Int("pr", 0, "Pull request number")
+		addFreezeFlags(cmd)
+	}
+	previewGCCmd.Flags().StringSlice("repo", nil, "Only check these repositories (default is the configured repositories previews are enabled for), can be repeated")
+	previewGCCmd.Flags().Bool("dry-run", false, "Only list what would be deleted")
+	previewGCCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	addFreezeFlags(previewGCCmd)
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/deploy"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// promotion copies the current deployment of one environment into another
+type promotion struct {
+	Org    string
+	Repo   string
+	From   string
+	To     string
+	Source types.EnvData
+	Reason string // why the promotion is refused, empty when it can go ahead
+}
+
+// promoteCmd represents the promote command
+var promoteCmd = &cobra.Command{
+	Use:   "promote",
+	Short: "Deploy what runs in one environment to the next",
+	Long: `Promote the latest successful deployment of one environment (e.g. test) to another (e.g. prod) by
+dispatching the deployment workflow of the target environment on the same ref. Failed and unfinished
+deployments of the source are skipped, as is done by compare.
+
+The promotion is refused when the source has no successful deployment, or when its ref has moved to
+another commit since it was deployed. The source defaults to promoteFrom of the target environment
+in the config, and --from must agree with it when both are set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		orgFlag, _ := cmd.Flags().GetString("org")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		repos, _ := cmd.Flags().GetStringSlice("repo")
+		all, _ := cmd.Flags().GetBool("all")
+		workflow, _ := cmd.Flags().GetString("workflow")
+		inputFlags, _ := cmd.Flags().GetStringArray("input")
+		envInput, _ := cmd.Flags().GetString("env-input")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		yes, _ := cmd.Flags().GetBool("yes")
+		correlationInput, _ := cmd.Flags().GetString("correlation-input")
+		if to == "" || (len(repos) == 0) == !all {
+			fmt.Println("Error: --to and either --repo or --all are required")
+			return
+		}
+
+		cfg, err := loadConfig()
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
+			return
+		}
+		inputs, err := deployInputs(inputFlags, envInput, to)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		var promotions []promotion
+		for _, orgName := range orgNames(cfg) {
+			if orgFlag != "" && orgName != orgFlag {
+				continue
+			}
+			for _, repoName := range slices.Sorted(maps.Keys(cfg.Orgs[orgName].Repos)) {
+				if _, ok := cfg.Orgs[orgName].Repos[repoName][to]; !ok || (!all && !slices.Contains(repos, repoName)) {
+					continue
+				}
+				p := planPromotion(orgName, repoName, from, to, cfg.Orgs[orgName].Repos[repoName][to])
+				if p.Reason == "" && !freezeAllows(cmd, cfg, orgName, repoName, to) {
+					p.Reason = "change freeze"
+				}
+				promotions = append(promotions, p)
+			}
+		}
+		if len(promotions) == 0 {
+			fmt.Printf("No configured repository has a %s environment to promote to\n", to)
+			return
+		}
+
+		ready := 0
+		for _, p := range promotions {
+			if p.Reason != "" {
+				fmt.Printf("  ✗ %s/%s %s → %s: %s\n", p.Org, p.Repo, p.From, p.To, p.Reason)
+				continue
+			}
+			ready++
+			fmt.Printf("  ✓ %s/%s %s → %s: %s (%s)\n", p.Org, p.Repo, p.From, p.To, p.Source.Ref, p.Source.SHA)
+		}
+		if ready == 0 {
+			fmt.Println("Nothing to promote")
+			os.Exit(1)
+		}
+		if !yes && !confirm(fmt.Sprintf("Promote %d repository(ies) to %s?", ready, to)) {
+			fmt.Println("Aborted")
+			return
+		}
+
+		summary := make(map[summaryKey]string)
+		failed := false
+		for _, p := range promotions {
+			key := summaryKey{p.Org, p.Repo, p.To, "promote", p.Source.Ref, shortSHA(p.Source.SHA)}
+			if p.Reason != "" {
+				summary[key] = "Refused"
+				failed = true
+				continue
+			}
+			request := deployRequest{Org: p.Org, Repo: p.Repo, Env: p.To, Ref: p.Source.Ref, Workflow: workflowFor(cfg, workflow, p.Org, p.Repo, p.To), Inputs: inputs, CorrelationInput: correlationInput}
+			run, err := dispatchDeploy(request)
+			if err == nil && wait {
+				run, err = waitForRun(p.Org, p.Repo, run, timeout)
+			}
+			switch {
+			case err != nil:
+				fmt.Printf("Error promoting %s/%s: %v\n", p.Org, p.Repo, err)
+				summary[key] = "error"
+				failed = true
+			case wait:
+				summary[key] = run.Conclusion
+				failed = failed || run.Conclusion != "success"
+			default:
+				summary[key] = "Dispatched"
+			}
+		}
+		printSummary(summary)
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// planPromotion finds the deployment to promote and checks it can be promoted
+func planPromotion(org, repo, from, to string, target types.DeploymentEnvOptions) promotion {
+	p := promotion{Org: org, Repo: repo, From: from, To: to}
+	source, err := deploy.PromotionSource(from, to, target)
+	if err != nil {
+		p.Reason = err.Error()
+		return p
+	}
+	p.From = source
+	if p.Source, err = currentDeployment(org, repo, p.From); err != nil {
+		p.Reason = err.Error()
+		return p
+	}
+
+	sha, err := api.ResolveRef(org, repo, p.Source.Ref)
+	if err != nil {
+		p.Reason = err.Error()
+		return p
+	}
+	if deploy.RefMoved(p.Source.SHA, sha) {
+		p.Reason = fmt.Sprintf("%s moved from %s to %s since it was deployed to %s", p.Source.Ref, shortSHA(p.Source.SHA), shortSHA(sha), p.From)
+	}
+	return p
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func init() {
+	rootCmd.AddCommand(promoteCmd)
+	promoteCmd.Flags().String("org", "", "Only promote repositories of this organization")
+	promoteCmd.Flags().String("from", "", "Environment to promote from (default is promoteFrom of the target environment)")
+	promoteCmd.Flags().String("to", "", "Environment to promote to")
+	promoteCmd.Flags().StringSlice("repo", nil, "Repository to promote, can be repeated")
+	promoteCmd.Flags().Bool("all", false, "Promote every configured repository with the target environment")
+	promoteCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	addDeployFlags(promoteCmd)
+	addFreezeFlags(promoteCmd)
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/deploy"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// pendingReview is a workflow run waiting on environments the current user can review
+type pendingReview struct {
+	Org          string
+	Repo         string
+	Run          api.WorkflowRun
+	Environments []api.PendingDeployment
+}
+
+// approveCmd represents the approve command
+var approveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Approve workflow runs waiting on an environment review",
+	Long:  reviewLong("Approve"),
+	Run: func(cmd *cobra.Command, args []string) {
+		reviewRuns(cmd, "approved")
+	},
+}
+
+// rejectCmd represents the reject command
+var rejectCmd = &cobra.Command{
+	Use:   "reject",
+	Short: "Reject workflow runs waiting on an environment review",
+	Long:  reviewLong("Reject"),
+	Run: func(cmd *cobra.Command, args []string) {
+		reviewRuns(cmd, "rejected")
+	},
+}
+
+func reviewLong(action string) string {
+	return action + ` deployments waiting on required reviewers without going through the web UI.
+
+With --run only that run of --repo is reviewed. Otherwise the waiting runs of every repository in
+the config (narrowed down by --repo, --env and --exclude) are listed to pick from. Only environments
+the token's user is allowed to review are included.`
+}
+
+// reviewRuns submits a review with the given state ("approved" or "rejected") for the selected runs
+func reviewRuns(cmd *cobra.Command, state string) {
+	runID, _ := cmd.Flags().GetInt("run")
+	comment, _ := cmd.Flags().GetString("comment")
+	yes, _ := cmd.Flags().GetBool("yes")
+	filter := filterFromFlags(cmd)
+	if comment == "" {
+		comment = fmt.Sprintf("%s with autogit", strings.ToUpper(state[:1])+state[1:])
+	}
+
+	var reviews []pendingReview
+	if runID != 0 {
+		if len(filter.Repos) != 1 {
+			fmt.Println("Error: --run needs exactly one --repo")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		run, err := api.GetWorkflowRun(org, filter.Repos[0], runID)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		review, err := reviewableEnvironments(org, filter.Repos[0], *run, filter)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if len(review.Environments) > 0 {
+			reviews = append(reviews, review)
+		}
+	} else {
+		cfg, err := loadFilteredConfig(cmd)
+		if err != nil {
+			fmt.Printf("Error parsing config: %v\n", err)
+			return
+		}
+		for _, orgName := range orgNames(cfg) {
+			for _, repoName := range slices.Sorted(maps.Keys(cfg.Orgs[orgName].Repos)) {
+				runs, err := api.FetchWaitingRuns(orgName, repoName)
+				if err != nil {
+					fmt.Printf("Error listing waiting runs for %s/%s: %v\n", orgName, repoName, err)
+					continue
+				}
+				for _, run := range runs {
+					review, err := reviewableEnvironments(orgName, repoName, run, filter)
+					if err != nil {
+						fmt.Printf("Error: %v\n", err)
+						continue
+					}
+					if len(review.Environments) > 0 {
+						reviews = append(reviews, review)
+					}
+				}
+			}
+		}
+	}
+
+	if len(reviews) == 0 {
+		fmt.Println("No workflow runs are waiting for your review")
+		return
+	}
+
+	var labels []string
+	for _, review := range reviews {
+		labels = append(labels, fmt.Sprintf("%s/%s run #%d %q on %s waiting on %s (%s)",
+			review.Org, review.Repo, review.Run.RunNumber, review.Run.DisplayTitle, review.Run.HeadBranch, environmentNames(review.Environments), review.Run.HTMLURL))
+	}
+	selected := make([]int, len(reviews))
+	for i := range selected {
+		selected[i] = i
+	}
+	if !yes {
+		if len(reviews) == 1 {
+			fmt.Println(labels[0])
+			if !confirm(fmt.Sprintf("Mark this run as %s?", state)) {
+				fmt.Println("Aborted")
+				return
+			}
+		} else {
+			selected = pick(labels, fmt.Sprintf("Runs to mark as %s", state))
+			if len(selected) == 0 {
+				fmt.Println("Aborted")
+				return
+			}
+		}
+	}
+
+	// Approving lets the deployment go ahead, so it is held back by change freezes like deploy
+	var freezeConfig types.Config
+	if state == "approved" {
+		freezeConfig = loadFreezeConfig()
+	}
+
+	summary := make(map[summaryKey]string)
+	for _, i := range selected {
+		review := reviews[i]
+		var ids []int
+		var reviewed []summaryKey
+		for _, pending := range review.Environments {
+			key := summaryKey{review.Org, review.Repo, pending.Environment.Name, "review", fmt.Sprintf("run #%d", review.Run.RunNumber), review.Run.HeadBranch}
+			if state == "approved" && !freezeAllows(cmd, freezeConfig, review.Org, review.Repo, pending.Environment.Name) {
+				summary[key] = "Frozen"
+				continue
+			}
+			ids = append(ids, pending.Environment.ID)
+			reviewed = append(reviewed, key)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		status := strings.ToUpper(state[:1]) + state[1:]
+		if err := api.ReviewPendingDeployments(review.Org, review.Repo, review.Run.ID, ids, state, comment); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			status = "error"
+		}
+		for _, key := range reviewed {
+			summary[key] = status
+		}
+	}
+	printSummary(summary)
+}
+
+// reviewableEnvironments returns the environments of a run the current user can review and the filter selects
+func reviewableEnvironments(org, repo string, run api.WorkflowRun, filter config.Filter) (pendingReview, error) {
+	review := pendingReview{Org: org, Repo: repo, Run: run}
+	pending, err := api.FetchPendingDeployments(org, repo, run.ID)
+	if err != nil {
+		return review, err
+	}
+	review.Environments = deploy.Reviewable(repo, pending, filter)
+	return review, nil
+}
+
+func environmentNames(pending []api.PendingDeployment) string {
+	var names []string
+	for _, deployment := range pending {
+		names = append(names, deployment.Environment.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// pick lists numbered items and reads a selection such as "1,3" or "all" from stdin
+func pick(items []string, question string) []int {
+	for i, item := range items {
+		fmt.Printf("  %d) %s\n", i+1, item)
+	}
+	fmt.Printf("%s (e.g. 1,3 or all, empty to abort): ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	selected, invalid := deploy.ParseSelection(answer, len(items))
+	for _, choice := range invalid {
+		fmt.Printf("Ignoring invalid choice %q\n", choice)
+	}
+	return selected
+}
+
+func init() {
+	for _, reviewCmd := range []*cobra.Command{approveCmd, rejectCmd} {
+		rootCmd.AddCommand(reviewCmd)
+		reviewCmd.Flags().String("org", "", "Organization of --repo when --run is given (default is the only organization in the config)")
+		reviewCmd.Flags().StringSlice("repo", nil, "Only review runs of repositories matching this glob, can be repeated")
+		reviewCmd.Flags().StringSlice("env", nil, "Only review deployments to environments matching this glob, can be repeated")
+		reviewCmd.Flags().StringSlice("exclude", nil, "Skip anything whose repo or repo/env matches this glob, can be repeated")
+		reviewCmd.Flags().Int("run", 0, "Workflow run ID to review, skipping the picker")
+		reviewCmd.Flags().String("comment", "", "Review comment")
+		reviewCmd.Flags().BoolP("yes", "y", false, "Review every matching run without asking")
+	}
+	addFreezeFlags(approveCmd)
+}
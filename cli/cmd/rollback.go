@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/deploy"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Redeploy the previous successful deployment of an environment",
+	Long: `Roll an environment back to the last successful deployment of another commit before the current one.
+
+The deployment history of the environment is read with its statuses, the change (refs, commits and
+release names) is shown, and the deployment workflow is dispatched on the previous ref. An audit line
+with the user of the token is printed, and appended to --audit-log when given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		repo, _ := cmd.Flags().GetString("repo")
+		env, _ := cmd.Flags().GetString("env")
+		workflow, _ := cmd.Flags().GetString("workflow")
+		inputFlags, _ := cmd.Flags().GetStringArray("input")
+		envInput, _ := cmd.Flags().GetString("env-input")
+		wait, _ := cmd.Flags().GetBool("wait")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		yes, _ := cmd.Flags().GetBool("yes")
+		auditLog, _ := cmd.Flags().GetString("audit-log")
+		correlationInput, _ := cmd.Flags().GetString("correlation-input")
+		if repo == "" || env == "" {
+			fmt.Println("Error: --repo and --env flags are required")
+			return
+		}
+		org, err := commandOrg(cmd)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		inputs, err := deployInputs(inputFlags, envInput, env)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		deployments, err := api.FetchEnvironmentDeployments(org, repo, env)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		current, previous, err := deploy.RollbackTarget(deployments, func(deploymentID int) ([]types.DeploymentStatus, error) {
+			return api.FetchDeploymentStatuses(org, repo, deploymentID)
+		})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		// Dispatches run on a branch or tag, so it must still point at the commit deployed back then
+		sha, err := api.ResolveRef(org, repo, previous.Ref)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if deploy.RefMoved(previous.SHA, sha) {
+			fmt.Printf("Error: %s moved from %s to %s since it was deployed, deploy a tag of %s instead\n", previous.Ref, shortSHA(previous.SHA), shortSHA(sha), shortSHA(previous.SHA))
+			return
+		}
+
+		releases, err := api.FetchReleases(org, repo)
+		if err != nil {
+			fmt.Printf("Could not fetch release names: %v\n", err)
+		}
+		fmt.Printf("Rolling back %s/%s/%s\n", org, repo, env)
+		fmt.Printf("  current:  %s (%s) %s deployed %s\n", current.Ref, shortSHA(current.SHA), releaseName(releases, current.Ref), current.CreatedAt.Format(time.RFC3339))
+		fmt.Printf("  rollback: %s (%s) %s deployed %s\n", previous.Ref, shortSHA(previous.SHA), releaseName(releases, previous.Ref), previous.CreatedAt.Format(time.RFC3339))
+		cfg := loadFreezeConfig()
+		if !freezeAllows(cmd, cfg, org, repo, env) {
+			os.Exit(1)
+		}
+		if !yes && !confirm("Roll back?") {
+			fmt.Println("Aborted")
+			return
+		}
+
+		request := deployRequest{Org: org, Repo: repo, Env: env, Ref: previous.Ref, Workflow: workflowFor(cfg, workflow, org, repo, env), Inputs: inputs, CorrelationInput: correlationInput}
+		run, err := dispatchDeploy(request)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		user, err := api.CurrentUser(org)
+		if err != nil {
+			user = "unknown"
+		}
+		audit := fmt.Sprintf("%s rollback %s/%s/%s by %s from %s (%s) to %s (%s) run %s\n",
+			time.Now().UTC().Format(time.RFC3339), org, repo, env, user, current.Ref, current.SHA, previous.Ref, previous.SHA, run.HTMLURL)
+		fmt.Print("AUDIT " + audit)
+		if auditLog != "" {
+			if err := appendLine(auditLog, audit); err != nil {
+				fmt.Printf("Error writing audit log: %v\n", err)
+			}
+		}
+
+		if wait {
+			if run, err = waitForRun(org, repo, run, timeout); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if run.Conclusion != "success" {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// releaseName returns the quoted name of the release tagged ref, or nothing
+func releaseName(releases []api.Release, ref string) string {
+	for _, release := range releases {
+		if release.TagName == ref && release.Name != "" {
+			return fmt.Sprintf("%q", release.Name)
+		}
+	}
+	return ""
+}
+
+func appendLine(path, line string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(line)
+	return err
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().String("org", "", "Organization of the repository (default is the only organization in the config)")
+	rollbackCmd.Flags().String("repo", "", "Repository to roll back")
+	rollbackCmd.Flags().String("env", "", "Deployment environment to roll back")
+	rollbackCmd.Flags().BoolP("yes", "y", false, "Skip the confirmation prompt")
+	rollbackCmd.Flags().String("audit-log", "", "Append the audit line to this file")
+	addDeployFlags(rollbackCmd)
+	addFreezeFlags(rollbackCmd)
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -28,13 +33,18 @@ func Execute() {
 
 func init() {
 	// Persistent flag for specifying configuration file
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "config.yaml", "Configuration file (default is config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "config.yaml", "Configuration file, directory of config files or glob pattern (default is config.yaml)")
 
 	// Bind Viper to config flag
 	cobra.OnInitialize(initConfig)
 }
 
 func initConfig() {
+	// Directories and glob patterns are merged by config.Load, viper only handles a single file
+	if info, err := os.Stat(cfgFile); (err == nil && info.IsDir()) || strings.ContainsAny(cfgFile, "*?[") {
+		return
+	}
+
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
@@ -43,8 +53,88 @@ func initConfig() {
 		viper.AddConfigPath(".")
 	}
 
+	// A missing default config.yaml is fine for commands driven purely by flags
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Printf("Error reading config file: %v\n", err)
-		os.Exit(1)
+		if rootCmd.PersistentFlags().Changed("config") || !errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("Error reading config file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// loadConfig parses the configuration file found by initConfig, registers the API host and token of
+// every organization, expands their repository selectors and interpolates `${{ ... }}` values
+func loadConfig() (types.Config, error) {
+	cfg, err := config.Load(configPath())
+	if err != nil {
+		return cfg, err
+	}
+	for _, orgName := range orgNames(cfg) {
+		org := cfg.Orgs[orgName]
+		api.RegisterOrg(orgName, org.APIHost, org.TokenEnv)
+		if len(org.RepoSelectors) > 0 {
+			if err := expandRepoSelectors(orgName, &org); err != nil {
+				return cfg, fmt.Errorf("failed to expand repoSelectors of %s: %w", orgName, err)
+			}
+		}
+		// Values are interpolated last so that repo.name is known for selected repositories
+		if err := config.InterpolateOrg(orgName, &org); err != nil {
+			return cfg, fmt.Errorf("failed to interpolate values: %w", err)
+		}
+		cfg.Orgs[orgName] = org
+	}
+	return cfg, nil
+}
+
+// configPath is the config file, directory or pattern given with --config
+func configPath() string {
+	if cfgFile != "" {
+		return cfgFile
 	}
+	return viper.ConfigFileUsed()
+}
+
+// orgNames returns the configured organizations in a stable order
+func orgNames(cfg types.Config) []string {
+	return config.OrgNames(cfg)
+}
+
+// expandRepoSelectors fetches the org repositories, and the teams and custom properties the
+// selectors refer to, and adds the selected environments to the organization's repos
+func expandRepoSelectors(orgName string, org *types.OrgConfig) error {
+	repos, err := api.ListOrgRepos(orgName)
+	if err != nil {
+		return err
+	}
+
+	teamRepos := make(map[string]map[string]bool)
+	needProperties := false
+	for _, selector := range org.RepoSelectors {
+		if len(selector.Match.CustomProperties) > 0 {
+			needProperties = true
+		}
+		if team := selector.Match.Team; team != "" && teamRepos[team] == nil {
+			repoList, err := api.ListTeamRepos(orgName, team)
+			if err != nil {
+				return err
+			}
+			teamRepos[team] = make(map[string]bool)
+			for _, repo := range repoList {
+				teamRepos[team][strings.ToLower(repo.Name)] = true
+			}
+		}
+	}
+
+	if needProperties {
+		properties, err := api.ListCustomPropertyValues(orgName)
+		if err != nil {
+			return err
+		}
+		for i := range repos {
+			repos[i].CustomProperties = properties[repos[i].Name]
+		}
+	}
+
+	config.ExpandRepoSelectors(org, repos, teamRepos)
+	return nil
 }
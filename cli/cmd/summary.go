@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/table"
+)
+
+// summaryKey is a row of the summary table. Names and values can contain slashes, e.g. a release/* branch policy.
+type summaryKey struct {
+	Org   string
+	Repo  string
+	Env   string
+	Kind  string // variable, secret, branch-policy and so on, N/A for the environment itself
+	Name  string
+	Value string
+}
+
+// printSummary renders the summary map, from row to status, as a table
+func printSummary(summary map[summaryKey]string) {
+	// Print summary
+	fmt.Printf("\n\n\n")
+
+	// Collect summary entries into a slice for sorting
+	type summaryEntry struct {
+		summaryKey
+		Status string
+	}
+	var summaryEntries []summaryEntry
+	for key, status := range summary {
+		summaryEntries = append(summaryEntries, summaryEntry{summaryKey: key, Status: status})
+	}
+	// Sort the summary entries by org, repo, env, kind and name
+	sort.Slice(summaryEntries, func(i, j int) bool {
+		if summaryEntries[i].Org != summaryEntries[j].Org {
+			return summaryEntries[i].Org < summaryEntries[j].Org
+		}
+		if summaryEntries[i].Repo != summaryEntries[j].Repo {
+			return summaryEntries[i].Repo < summaryEntries[j].Repo
+		}
+		if summaryEntries[i].Env != summaryEntries[j].Env {
+			return summaryEntries[i].Env < summaryEntries[j].Env
+		}
+		if summaryEntries[i].Kind != summaryEntries[j].Kind {
+			return summaryEntries[i].Kind < summaryEntries[j].Kind
+		}
+		return summaryEntries[i].Name < summaryEntries[j].Name
+	})
+
+	// Print summary using table package for better outputformatting
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Org", "Repo", "Environment", "Var/Secret", "Name", "Value", "Status"})
+
+	for _, entry := range summaryEntries {
+		status := entry.Status
+		if status == "error" {
+			status = fmt.Sprintf("%s ❌", status)
+		} else if status == "Unchanged" {
+			status = fmt.Sprintf("%s 🆗", status)
+		} else if status == "Changed" {
+			status = fmt.Sprintf("%s 💣", status)
+		} else if status == "Created" {
+			status = fmt.Sprintf("%s ✅", status)
+		} else if status == "Created&Updated" {
+			status = fmt.Sprintf("%s 🔄", status) // This is mainly for Deployment Environments are Create and Update are the same PUT operation on the same API
+		} else if status == "Deleted" {
+			status = fmt.Sprintf("%s 🗑️", status)
+		} else if status == "Frozen" {
+			status = fmt.Sprintf("%s 🧊", status)
+		}
+		t.AppendRow([]interface{}{entry.Org, entry.Repo, entry.Env, entry.Kind, entry.Name, entry.Value, status})
+	}
+	t.SetStyle(table.StyleColoredBlackOnYellowWhite)
+	t.Render()
+}
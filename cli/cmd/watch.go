@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/watch"
+	"github.com/spf13/cobra"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Follow deployments of the configured environments live",
+	Long: `Poll the deployments and deployment statuses of the configured repositories and environments,
+narrowed down by --repo, --env and --exclude, and print a line (or a JSON event with --json) for every
+new deployment and status change. Requests are conditional (ETags), so polls without changes are cheap.
+Stop with Ctrl-C.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		depth, _ := cmd.Flags().GetInt("depth")
+		// Errors go to stderr so they never end up in the --json stream
+		if interval <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --interval must be greater than zero")
+			os.Exit(1)
+		}
+		if depth <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: --depth must be greater than zero")
+			os.Exit(1)
+		}
+		cfg, err := loadFilteredConfig(cmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing config: %v\n", err)
+			os.Exit(1)
+		}
+
+		type target struct {
+			Org          string
+			Repo         string
+			Environments map[string]types.DeploymentEnvOptions
+		}
+		var targets []target
+		for _, orgName := range orgNames(cfg) {
+			for _, repoName := range slices.Sorted(maps.Keys(cfg.Orgs[orgName].Repos)) {
+				targets = append(targets, target{Org: orgName, Repo: repoName, Environments: cfg.Orgs[orgName].Repos[repoName]})
+			}
+		}
+		if len(targets) == 0 {
+			fmt.Fprintln(os.Stderr, "No repositories to watch")
+			return
+		}
+		if !asJSON {
+			fmt.Printf("Watching %d repositories every %s\n", len(targets), interval)
+		}
+
+		tracker := watch.NewTracker()
+		etags := make(map[string]string)
+		latest := make(map[string][]types.Deployment)
+		for {
+			for _, t := range targets {
+				key := t.Org + "/" + t.Repo
+				deployments, etag, changed, err := api.WatchDeployments(t.Org, t.Repo, etags[key])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error watching %s: %v\n", key, err)
+					continue
+				}
+				etags[key] = etag
+				if changed {
+					// Only configured environments are followed, keeping at most depth deployments each
+					var followed []types.Deployment
+					perEnv := make(map[string]int)
+					for _, deployment := range deployments {
+						if _, ok := t.Environments[deployment.Environment]; ok && perEnv[deployment.Environment] < depth {
+							perEnv[deployment.Environment]++
+							followed = append(followed, deployment)
+						}
+					}
+					// Deployments that dropped out of the window are no longer polled, so their ETags can go
+					for _, previous := range latest[key] {
+						if !slices.ContainsFunc(followed, func(d types.Deployment) bool { return d.ID == previous.ID }) {
+							delete(etags, statusETagKey(key, previous.ID))
+						}
+					}
+					latest[key] = followed
+					printWatchEvents(tracker.Deployments(t.Org, t.Repo, followed), asJSON)
+				}
+
+				for _, deployment := range latest[key] {
+					statusKey := statusETagKey(key, deployment.ID)
+					statuses, etag, changed, err := api.WatchDeploymentStatuses(t.Org, t.Repo, deployment.ID, etags[statusKey])
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Error watching deployment %d of %s: %v\n", deployment.ID, key, err)
+						continue
+					}
+					etags[statusKey] = etag
+					if changed {
+						printWatchEvents(tracker.Statuses(t.Org, t.Repo, deployment.ID, statuses), asJSON)
+					}
+				}
+			}
+			time.Sleep(interval)
+		}
+	},
+}
+
+// statusETagKey is the key of a deployment's status ETag, next to the org/repo keys of the deployment lists
+func statusETagKey(repoKey string, deploymentID int) string {
+	return fmt.Sprintf("%s#%d", repoKey, deploymentID)
+}
+
+// printWatchEvents prints one line, or one JSON object, per event
+func printWatchEvents(events []watch.Event, asJSON bool) {
+	for _, event := range events {
+		if asJSON {
+			data, _ := json.Marshal(event)
+			fmt.Println(string(data))
+			continue
+		}
+		line := fmt.Sprintf("%s  %s/%s  %-10s  deployment %d  %s (%s)", event.Time.Local().Format(time.DateTime), event.Org, event.Repo, event.Environment, event.DeploymentID, event.Ref, shortSHA(event.SHA))
+		if event.Type == "deployment" {
+			line += "  created"
+		} else {
+			line += "  " + event.State
+			if event.Creator != "" {
+				line += " by " + event.Creator
+			}
+			if event.LogURL != "" {
+				line += "  " + event.LogURL
+			}
+		}
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringSlice("repo", nil, "Only watch repositories matching this glob (org/repo patterns match the org too), can be repeated")
+	watchCmd.Flags().StringSlice("env", nil, "Only watch environments matching this glob, can be repeated")
+	watchCmd.Flags().StringSlice("exclude", nil, "Skip anything whose repo or repo/env matches this glob, can be repeated")
+	watchCmd.Flags().Duration("interval", 30*time.Second, "Time between polls")
+	watchCmd.Flags().Bool("json", false, "Print every change as a JSON object on its own line")
+	watchCmd.Flags().Int("depth", 5, "Number of recent deployments per environment whose statuses are followed")
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Comparison is the difference between two commits as returned by the compare endpoint.
+type Comparison struct {
+	Status       string          `json:"status"` // ahead, behind, diverged or identical
+	AheadBy      int             `json:"ahead_by"`
+	BehindBy     int             `json:"behind_by"`
+	TotalCommits int             `json:"total_commits"`
+	HTMLURL      string          `json:"html_url"`
+	Commits      []CompareCommit `json:"commits"`
+	Files        []ChangedFile   `json:"files"`
+}
+
+// CompareCommit is a commit of a comparison.
+type CompareCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+			Date string `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// ChangedFile is a file changed between the two commits of a comparison.
+type ChangedFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// CompareCommits compares base with head, listing the commits (up to 250) and files that head adds.
+func CompareCommits(org, repo, base, head string) (*Comparison, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s", apiURL(org), org, repo, url.PathEscape(base), url.PathEscape(head))
+	var comparison Comparison
+	if err := getJSON(org, uri, &comparison); err != nil {
+		return nil, fmt.Errorf("failed to compare %s...%s: %w", base, head, err)
+	}
+	return &comparison, nil
+}
+
+// FetchCommitPullRequests retrieves the pull requests a commit belongs to.
+func FetchCommitPullRequests(org, repo, sha string) ([]PullRequest, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/commits/%s/pulls", apiURL(org), org, repo, sha)
+	var pulls []PullRequest
+	if err := getJSON(org, uri, &pulls); err != nil {
+		return nil, fmt.Errorf("failed to fetch pull requests of %s: %w", sha, err)
+	}
+	return pulls, nil
+}
+
+// getJSON sends an authenticated GET and decodes the JSON response into into
+func getJSON(org, uri string, into interface{}) error {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %s: %s", resp.Status, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(into)
+}
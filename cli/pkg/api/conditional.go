@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// conditionalGet fetches uri with If-None-Match so that unchanged resources cost a 304 instead of a full
+// response (304s do not count against the rate limit). It returns the new ETag and whether into was filled.
+func conditionalGet(org, uri, etag string, into interface{}) (string, bool, error) {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return etag, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return etag, false, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return etag, false, nil
+	case http.StatusOK:
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return etag, false, fmt.Errorf("failed to fetch %s: %s", uri, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+		return etag, false, fmt.Errorf("failed to decode %s: %w", uri, err)
+	}
+	return resp.Header.Get("ETag"), true, nil
+}
+
+// WatchDeployments fetches the latest deployments of a repository unless they did not change since etag.
+// changed is false, and deployments nil, when GitHub answered 304 Not Modified.
+func WatchDeployments(org, repo, etag string) (deployments []types.Deployment, newETag string, changed bool, err error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments?per_page=30", apiURL(org), org, repo)
+	newETag, changed, err = conditionalGet(org, uri, etag, &deployments)
+	return deployments, newETag, changed, err
+}
+
+// WatchDeploymentStatuses fetches the statuses of a deployment unless they did not change since etag.
+func WatchDeploymentStatuses(org, repo string, deploymentID int, etag string) (statuses []types.DeploymentStatus, newETag string, changed bool, err error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments/%d/statuses?per_page=100", apiURL(org), org, repo, deploymentID)
+	newETag, changed, err = conditionalGet(org, uri, etag, &statuses)
+	return statuses, newETag, changed, err
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// CreateBranchPolicy adds a deployment branch or tag policy to an environment, leaving existing policies untouched.
+// The environment needs deploymentBranchPolicy.customBranchPolicies set for GitHub to accept it.
+func CreateBranchPolicy(org, repo, env string, policy types.BranchPolicy, existing []types.BranchPolicy) (string, error) {
+	if policy.Type == "" {
+		policy.Type = "branch"
+	}
+	for _, current := range existing {
+		if current.Name == policy.Name && strings.EqualFold(current.Type, policy.Type) {
+			return "Unchanged", nil
+		}
+	}
+
+	body, err := json.Marshal(policy)
+	if err != nil {
+		return "error", fmt.Errorf("failed to encode branch policy %s: %w", policy.Name, err)
+	}
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/deployment-branch-policies", apiURL(org), org, repo, env)
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(body))
+	if err != nil {
+		return "error", fmt.Errorf("failed to create branch policy request: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "error", fmt.Errorf("failed to send request to branch policy API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "error", fmt.Errorf("failed to create branch policy %s for %s/%s: %s", policy.Name, repo, env, body)
+	}
+
+	fmt.Printf("Created %s policy %s for %s in %s\n", policy.Type, policy.Name, env, repo)
+	return "Created", nil
+}
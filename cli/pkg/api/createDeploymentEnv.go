@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,28 +10,41 @@ import (
 	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
 )
 
+// environmentRequest is the body of the create or update environment endpoint.
+// Nil fields are omitted, so zero values set in the config are sent and clear the rule.
+type environmentRequest struct {
+	WaitTimer              *int                          `json:"wait_timer,omitempty"`
+	PreventSelfReview      *bool                         `json:"prevent_self_review,omitempty"`
+	Reviewers              *[]types.Reviewer             `json:"reviewers,omitempty"`
+	DeploymentBranchPolicy *types.DeploymentBranchPolicy `json:"deployment_branch_policy,omitempty"`
+}
+
 func CreateDeploymentEnv(org, repo, env string, envOptions types.DeploymentEnvOptions) (string, error) {
 	var status string
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s", org, repo, env)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s", apiURL(org), org, repo, env)
+	// Only protection rules set in the config are sent, anything else is left as it is in GitHub
+	request := environmentRequest{
+		WaitTimer:              envOptions.WaitTimer,
+		PreventSelfReview:      envOptions.PreventSelfReview,
+		DeploymentBranchPolicy: envOptions.DeploymentBranchPolicy,
+	}
+	if envOptions.Reviewers != nil {
+		request.Reviewers = &envOptions.Reviewers
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "error:", fmt.Errorf("failed to encode environment %s: %w", env, err)
+	}
 	// Create a new request using http.NewRequest() and set the Authorization header
-	req, err := http.NewRequest("PUT", uri, nil)
+	req, err := http.NewRequest("PUT", uri, bytes.NewReader(body))
 	if err != nil {
 		status = "error"
 		return "error:", fmt.Errorf("failed to create deployment environment: %w", err)
 	}
 
-	req.Header.Add("Authorization", "bearer "+SetHeader())
-	// req.Body = io.NopCloser(
-	// 	strings.NewReader(fmt.Sprintln(`{
-	// 			"prevent_self_review": false,
-	// 			// "reviewers": [
-	// 			// 	"type": "Team",
-	// 			// 	"id": 12345
-	// 			// 	"type": "User",
-	// 			// 	"id": 113201461
-	// 			// ]
-	// 		}`)),
-	// )
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
 	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -51,13 +65,13 @@ func CreateDeploymentEnv(org, repo, env string, envOptions types.DeploymentEnvOp
 }
 
 func CheckDeployEnv(org, repo, env string, envOptions types.DeploymentEnvOptions) ([]types.EnvCheck, error) {
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s", org, repo, env)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s", apiURL(org), org, repo, env)
 	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create deployment environment: %w", err)
 	}
 
-	req.Header.Add("Authorization", "bearer "+SetHeader())
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
 	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -79,3 +93,30 @@ func CheckDeployEnv(org, repo, env string, envOptions types.DeploymentEnvOptions
 
 	return envCheck, nil
 }
+
+// DeleteDeploymentEnv deletes a deployment environment along with its secrets, variables and protection rules.
+func DeleteDeploymentEnv(org, repo, env string) (string, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s", apiURL(org), org, repo, env)
+	req, err := http.NewRequest("DELETE", uri, nil)
+	if err != nil {
+		return "error", fmt.Errorf("failed to create DELETE request for environment: %w", err)
+	}
+
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "error", fmt.Errorf("failed to send DELETE request to environment API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return "error", fmt.Errorf("failed to delete environment '%s': %s", env, body)
+	}
+
+	fmt.Printf("Deleted deployment environment %s in %s\n", env, repo)
+	return "Deleted", nil
+}
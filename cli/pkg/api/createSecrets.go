@@ -1,26 +1,34 @@
 package api
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 
+	"github.com/MarkDevOps/AutoGit/cli/pkg/state"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
 	"golang.org/x/crypto/nacl/box"
 )
 
+// secretRequest is the body of the create or update secret endpoint
+type secretRequest struct {
+	EncryptedValue string `json:"encrypted_value"`
+	KeyID          string `json:"key_id"`
+}
+
 func GetGithubPublicKey(org, repo, env string) (interface{}, error) {
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s/secrets/public-key", org, repo, env)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/secrets/public-key", apiURL(org), org, repo, env)
 	// Create a new request using http.NewRequest() and set the Authorization header
 	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get public key: %w", err)
 	}
 	// Set the Authorization header using req.Header.Set()
-	req.Header.Add("Authorization", "bearer "+SetHeader())
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
 	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -88,25 +96,21 @@ func CreateUpdateSecret(org, repo, env, secret, value, publickey, publickey_id s
 		return "error:", fmt.Errorf("failed to encrypt value: %w", err)
 	}
 
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s/secrets/%s", org, repo, env, secret)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/secrets/%s", apiURL(org), org, repo, env, secret)
 	// Create a new request using http.NewRequest() and set the Authorization header
 	req, err := http.NewRequest("PUT", uri, nil)
 	if err != nil {
 		return "error:", fmt.Errorf("failed to sent PUT API request for create/update secrets: %w", err)
 	}
 	// Set the Authorization header using req.Header.Set()
-	req.Header.Add("Authorization", "bearer "+SetHeader())
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
 	req.Header.Add("Accept", "application/vnd.github+json")
 	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
-	req.Body = io.NopCloser(
-		strings.NewReader(fmt.Sprintf(`
-			{
-				"encrypted_value":"%s",
-				"key_id":"%s"
-			}
-		`, encryptedValue, publickey_id)),
-		// strings.NewReader(fmt.Sprintf(`{"value": "%s"}`, value)),
-	)
+	body, err := json.Marshal(secretRequest{EncryptedValue: encryptedValue, KeyID: publickey_id})
+	if err != nil {
+		return "error:", fmt.Errorf("failed to encode secret '%s': %w", secret, err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
 	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -130,3 +134,137 @@ func CreateUpdateSecret(org, repo, env, secret, value, publickey, publickey_id s
 	fmt.Printf("Creating secret for %s in %s\n", secret, repo)
 	return status, nil
 }
+
+// GetSecret returns the metadata of an environment secret, or nil if it does not exist.
+func GetSecret(org, repo, env, secret string) (*types.SecretMetadata, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/secrets/%s", apiURL(org), org, repo, env, secret)
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send GET to secrets API: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to secret API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get secret '%s': %s", secret, body)
+	}
+
+	var metadata types.SecretMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode secret: %w", err)
+	}
+	return &metadata, nil
+}
+
+// CreateUpdateSecretIfChanged only PUTs the secret when its value fingerprint or GitHub's
+// updated_at differ from what is recorded in the state file, and records the new values afterwards.
+// With a nil state it behaves exactly like CreateUpdateSecret.
+func CreateUpdateSecretIfChanged(org, repo, env, secret, value, publickey, publickey_id string, secretState *state.State) (string, error) {
+	if secretState == nil {
+		return CreateUpdateSecret(org, repo, env, secret, value, publickey, publickey_id)
+	}
+
+	key := state.SecretKey(org, repo, env, secret)
+	existing, err := GetSecret(org, repo, env, secret)
+	if err != nil {
+		return "error", err
+	}
+	if existing != nil && secretState.Unchanged(key, value, existing.UpdatedAt) {
+		fmt.Printf("secret already exists with same value: %s\n", secret)
+		return "Unchanged", nil
+	}
+
+	status, err := CreateUpdateSecret(org, repo, env, secret, value, publickey, publickey_id)
+	if err != nil {
+		return status, err
+	}
+
+	updated, err := GetSecret(org, repo, env, secret)
+	if err != nil {
+		return "error", fmt.Errorf("secret '%s' was written but its metadata could not be fetched: %w", secret, err)
+	}
+	if updated != nil {
+		secretState.Record(key, value, updated.UpdatedAt)
+	}
+	// A secret written for the first time into the state file was most likely already there
+	// with the same value, but we cannot know that, so it is still reported as Changed.
+	return status, nil
+}
+
+// DeleteSecret deletes an environment secret.
+func DeleteSecret(org, repo, env, secret string) (string, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/secrets/%s", apiURL(org), org, repo, env, secret)
+	req, err := http.NewRequest("DELETE", uri, nil)
+	if err != nil {
+		return "error", fmt.Errorf("failed to create DELETE request for secret: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "error", fmt.Errorf("failed to send request to secret API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return "error", fmt.Errorf("failed to delete secret '%s': %s", secret, body)
+	}
+
+	fmt.Printf("Deleted secret %s in %s/%s\n", secret, repo, env)
+	return "Deleted", nil
+}
+
+// ListSecrets retrieves the names of all secrets of an environment, following pagination.
+func ListSecrets(org, repo, env string) ([]types.SecretMetadata, error) {
+	var secrets []types.SecretMetadata
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/secrets?per_page=100&page=%d", apiURL(org), org, repo, env, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send GET to secrets API: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to secret API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list secrets: %s", body)
+		}
+
+		var response struct {
+			TotalCount int                    `json:"total_count"`
+			Secrets    []types.SecretMetadata `json:"secrets"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secrets: %w", err)
+		}
+
+		secrets = append(secrets, response.Secrets...)
+		if len(response.Secrets) == 0 || len(secrets) >= response.TotalCount {
+			return secrets, nil
+		}
+	}
+}
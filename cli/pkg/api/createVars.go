@@ -1,15 +1,23 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
 )
 
+// variableRequest is the body of the create and update variable endpoints
+type variableRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
 func ShowVariables(org, repo, env, variable string) (interface{}, error) {
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s/variables/%s", org, repo, env, variable)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/variables/%s", apiURL(org), org, repo, env, variable)
 	// Create a new request using http.NewRequest() and set the Authorization header
 	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
@@ -17,7 +25,7 @@ func ShowVariables(org, repo, env, variable string) (interface{}, error) {
 	}
 
 	// Set the Authorization header using req.Header.Add()
-	req.Header.Add("Authorization", "bearer "+SetHeader())
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -40,7 +48,7 @@ func ShowVariables(org, repo, env, variable string) (interface{}, error) {
 }
 
 func PatchVariable(org, repo, env, variable, value string) error {
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s/variables/%s", org, repo, env, variable)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/variables/%s", apiURL(org), org, repo, env, variable)
 	// Create a new request using http.NewRequest() and set the Authorization header
 	req, err := http.NewRequest("PATCH", uri, nil)
 	if err != nil {
@@ -48,15 +56,12 @@ func PatchVariable(org, repo, env, variable, value string) error {
 	}
 
 	// set the Authorization header using req.Header.Add()
-	req.Header.Add("Authorization", "bearer "+SetHeader())
-	req.Body = io.NopCloser(
-		strings.NewReader(fmt.Sprintf(`
-			{
-				"value":"%s",
-				"name":"%s"
-			}
-		`, value, variable)),
-	)
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	body, err := json.Marshal(variableRequest{Name: variable, Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode variable %s: %w", variable, err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -74,7 +79,7 @@ func PatchVariable(org, repo, env, variable, value string) error {
 
 func CreateUpdateVariable(org, repo, env, variable, value string) (string, error) {
 	var status string
-	uri := fmt.Sprintf("https://api.github.com/repos/%s/%s/environments/%s/variables", org, repo, env)
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/variables", apiURL(org), org, repo, env)
 	// Create a new request using http.NewRequest() and set the Authorization header
 	req, err := http.NewRequest("POST", uri, nil)
 	if err != nil {
@@ -83,16 +88,15 @@ func CreateUpdateVariable(org, repo, env, variable, value string) (string, error
 	}
 
 	// Set the Authorization header using req.Header.Add()
-	req.Header.Add("Authorization", "bearer "+SetHeader())
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
 	req.Header.Add("Accept", "application/vnd.github+json")
 	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
-	req.Body = io.NopCloser(strings.NewReader(fmt.Sprintf(`
-			{
-				"name":"%s",
-				"value":"%s"
-			}
-		`, variable, value)),
-	)
+	body, err := json.Marshal(variableRequest{Name: variable, Value: value})
+	if err != nil {
+		status = "error"
+		return "error", fmt.Errorf("failed to encode variable %s: %w", variable, err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -112,12 +116,12 @@ func CreateUpdateVariable(org, repo, env, variable, value string) (string, error
 		fmt.Printf("variable already exists: %s", variable)
 		fmt.Printf("\nfetching variable: %s", variable)
 		existingVariable, err := ShowVariables(org, repo, env, variable)
-		fmt.Printf("\nexisting variable: '%s': '%v'\n", variable, existingVariable.(map[string]interface{})["value"].(string))
 		if err != nil {
 			status = "error"
 			return "error", fmt.Errorf("failed to show existing variable: %w", err)
 		}
-		if existingVariable.(map[string]interface{})["value"].(string) == value {
+		fmt.Printf("\nexisting variable: '%s': '%v'\n", variable, existingVariable.(map[string]interface{})["value"].(string))
+		if types.SameValue(value, existingVariable.(map[string]interface{})["value"].(string)) {
 			fmt.Printf("variable already exists with same value: %s\n\n", variable)
 			status = "Unchanged"
 		} else {
@@ -132,3 +136,82 @@ func CreateUpdateVariable(org, repo, env, variable, value string) (string, error
 	fmt.Printf("Creating variable for `%s` in %s/%s/%s\n", variable, org, repo, env)
 	return status, nil
 }
+
+// DeleteVariable deletes an environment variable.
+func DeleteVariable(org, repo, env, variable string) (string, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/variables/%s", apiURL(org), org, repo, env, variable)
+	req, err := http.NewRequest("DELETE", uri, nil)
+	if err != nil {
+		return "error", fmt.Errorf("failed to create DELETE request for variable: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "error", fmt.Errorf("failed to send request to variables API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return "error", fmt.Errorf("failed to delete variable %s: %s", variable, resp.Status)
+	}
+
+	fmt.Printf("Deleted variable %s in %s/%s\n", variable, repo, env)
+	return "Deleted", nil
+}
+
+// ListVariables retrieves all variables of an environment with their values, following pagination.
+func ListVariables(org, repo, env string) ([]types.Variable, error) {
+	return listVariables(org, fmt.Sprintf("%s/repos/%s/%s/environments/%s/variables", apiURL(org), org, repo, env))
+}
+
+// ListRepoVariables retrieves the repository level Actions variables.
+func ListRepoVariables(org, repo string) ([]types.Variable, error) {
+	return listVariables(org, fmt.Sprintf("%s/repos/%s/%s/actions/variables", apiURL(org), org, repo))
+}
+
+// ListOrgVariables retrieves the organization variables shared with a repository.
+func ListOrgVariables(org, repo string) ([]types.Variable, error) {
+	return listVariables(org, fmt.Sprintf("%s/repos/%s/%s/actions/organization-variables", apiURL(org), org, repo))
+}
+
+func listVariables(org, baseURI string) ([]types.Variable, error) {
+	var variables []types.Variable
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s?per_page=30&page=%d", baseURI, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send GET to variables Api: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to variables API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list variables: %s", resp.Status)
+		}
+
+		var response struct {
+			TotalCount int              `json:"total_count"`
+			Variables  []types.Variable `json:"variables"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode variables: %w", err)
+		}
+
+		variables = append(variables, response.Variables...)
+		if len(response.Variables) == 0 || len(variables) >= response.TotalCount {
+			return variables, nil
+		}
+	}
+}
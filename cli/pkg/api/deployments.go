@@ -1,26 +1,92 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
 )
 
+// FetchDeployments retrieves every deployment of a repository, newest first, following pagination.
 func FetchDeployments(org, repo string) ([]types.Deployment, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/deployments", org, repo)
+	var deployments []types.Deployment
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/repos/%s/%s/deployments?per_page=100&page=%d", apiURL(org), org, repo, page)
+
+		// Create a new request using http.NewRequest() and set the Authorization header
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch deployments: %w", err)
+		}
+		// Set the Authorization header using req.Header.Set()
+		req.Header.Set("Authorization", "bearer "+orgToken(org))
+
+		// Send the request using http.DefaultClient.Do() and check the response
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch deployments: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to fetch deployments: %s", body)
+		}
 
-	// Create a new request using http.NewRequest() and set the Authorization header
-	req, err := http.NewRequest("GET", url, nil)
+		var pageDeployments []types.Deployment
+		err = json.NewDecoder(resp.Body).Decode(&pageDeployments)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode deployments: %w", err)
+		}
+
+		deployments = append(deployments, pageDeployments...)
+		if len(pageDeployments) < 100 {
+			return deployments, nil
+		}
+	}
+}
+
+// DeleteDeployment deletes a deployment. GitHub only deletes inactive deployments, or the only one of a repository.
+func DeleteDeployment(org, repo string, deploymentID int) error {
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments/%d", apiURL(org), org, repo, deploymentID)
+	req, err := http.NewRequest("DELETE", uri, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create DELETE request for deployment: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send DELETE request to deployments API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete deployment %d: %s", deploymentID, body)
+	}
+	return nil
+}
+
+// FetchEnvironmentDeployments retrieves the most recent deployments to one environment, newest first.
+func FetchEnvironmentDeployments(org, repo, env string) ([]types.Deployment, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments?environment=%s&per_page=100", apiURL(org), org, repo, url.QueryEscape(env))
+	req, err := http.NewRequest("GET", uri, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch deployments: %w", err)
 	}
-	// Set the Authorization header using req.Header.Set()
-	req.Header.Set("Authorization", "bearer "+SetHeader())
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
 
-	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch deployments: %w", err)
@@ -28,7 +94,7 @@ func FetchDeployments(org, repo string) ([]types.Deployment, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
+		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("failed to fetch deployments: %s", body)
 	}
 
@@ -36,6 +102,180 @@ func FetchDeployments(org, repo string) ([]types.Deployment, error) {
 	if err := json.NewDecoder(resp.Body).Decode(&deployments); err != nil {
 		return nil, fmt.Errorf("failed to decode deployments: %w", err)
 	}
-
 	return deployments, nil
 }
+
+// FetchDeploymentStatuses retrieves the statuses of a deployment, newest first.
+func FetchDeploymentStatuses(org, repo string, deploymentID int) ([]types.DeploymentStatus, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments/%d/statuses?per_page=100", apiURL(org), org, repo, deploymentID)
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployment statuses: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployment statuses: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch statuses of deployment %d: %s", deploymentID, body)
+	}
+
+	var statuses []types.DeploymentStatus
+	if err := json.NewDecoder(resp.Body).Decode(&statuses); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment statuses: %w", err)
+	}
+	return statuses, nil
+}
+
+// LatestDeploymentState returns the state of the newest status of a deployment, or "pending" when it has none.
+func LatestDeploymentState(org, repo string, deploymentID int) (string, error) {
+	statuses, err := FetchDeploymentStatuses(org, repo, deploymentID)
+	if err != nil {
+		return "", err
+	}
+	if len(statuses) == 0 {
+		return "pending", nil
+	}
+	return statuses[0].State, nil
+}
+
+// ResolveRef returns the commit SHA a branch, tag or SHA currently points to.
+func ResolveRef(org, repo, ref string) (string, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/commits/%s", apiURL(org), org, repo, url.PathEscape(ref))
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ref: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github.sha")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ref: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to resolve ref %s: %s", ref, body)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// CreateDeployment records a deployment of ref to an environment, e.g. for deploys made outside Actions.
+// Commit status checks are not required as the deploy already happened elsewhere.
+func CreateDeployment(org, repo, ref, env, description string, payload json.RawMessage) (*types.Deployment, error) {
+	body, err := json.Marshal(struct {
+		Ref              string          `json:"ref"`
+		Environment      string          `json:"environment"`
+		Description      string          `json:"description,omitempty"`
+		Payload          json.RawMessage `json:"payload,omitempty"`
+		AutoMerge        bool            `json:"auto_merge"`
+		RequiredContexts []string        `json:"required_contexts"`
+	}{Ref: ref, Environment: env, Description: description, Payload: payload, RequiredContexts: []string{}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode deployment: %w", err)
+	}
+
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments", apiURL(org), org, repo)
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deployment request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to deployments API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to create deployment of %s to %s: %s", ref, env, body)
+	}
+
+	var deployment types.Deployment
+	if err := json.NewDecoder(resp.Body).Decode(&deployment); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment: %w", err)
+	}
+	return &deployment, nil
+}
+
+// CreateDeploymentStatus sets the state of a deployment: error, failure, inactive, in_progress, queued, pending or success.
+func CreateDeploymentStatus(org, repo string, deploymentID int, status types.DeploymentStatus) (*types.DeploymentStatus, error) {
+	body, err := json.Marshal(struct {
+		State          string `json:"state"`
+		Description    string `json:"description,omitempty"`
+		LogURL         string `json:"log_url,omitempty"`
+		EnvironmentURL string `json:"environment_url,omitempty"`
+		AutoInactive   *bool  `json:"auto_inactive,omitempty"`
+	}{State: status.State, Description: status.Description, LogURL: status.LogURL, EnvironmentURL: status.EnvironmentURL, AutoInactive: status.AutoInactive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode deployment status: %w", err)
+	}
+
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments/%d/statuses", apiURL(org), org, repo, deploymentID)
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deployment status request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to deployment statuses API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to set deployment %d to %s: %s", deploymentID, status.State, body)
+	}
+
+	var created types.DeploymentStatus
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment status: %w", err)
+	}
+	return &created, nil
+}
+
+// GetDeployment retrieves a single deployment.
+func GetDeployment(org, repo string, deploymentID int) (*types.Deployment, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/deployments/%d", apiURL(org), org, repo, deploymentID)
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployment: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch deployment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch deployment %d: %s", deploymentID, body)
+	}
+
+	var deployment types.Deployment
+	if err := json.NewDecoder(resp.Body).Decode(&deployment); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment: %w", err)
+	}
+	return &deployment, nil
+}
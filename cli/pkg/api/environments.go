@@ -1,6 +1,10 @@
 package api
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
@@ -31,8 +35,121 @@ func MapEnvironmentData(deployments []types.Deployment, envName string, envOptio
 	return types.EnvData{
 		DeploymentID:  latestDeployment.ID,
 		Ref:           latestDeployment.Ref,
+		SHA:           latestDeployment.SHA,
 		Description:   latestDeployment.Description,
 		CreatedAt:     latestDeployment.CreatedAt.Format(time.RFC3339),
 		DeploymentURL: latestDeployment.StatusesURL,
 	}
 }
+
+// ListEnvironments retrieves all deployment environments of a repository, following pagination.
+func ListEnvironments(org, repo string) ([]types.Environment, error) {
+	var environments []types.Environment
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/repos/%s/%s/environments?per_page=100&page=%d", apiURL(org), org, repo, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list environments: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to environment API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list environments for %s: %s", repo, body)
+		}
+
+		var response struct {
+			TotalCount   int                 `json:"total_count"`
+			Environments []types.Environment `json:"environments"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode environments: %w", err)
+		}
+
+		environments = append(environments, response.Environments...)
+		if len(response.Environments) == 0 || len(environments) >= response.TotalCount {
+			return environments, nil
+		}
+	}
+}
+
+// ListBranchPolicies retrieves the custom deployment branch and tag policies of an environment.
+func ListBranchPolicies(org, repo, env string) ([]types.BranchPolicy, error) {
+	var policies []types.BranchPolicy
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/repos/%s/%s/environments/%s/deployment-branch-policies?per_page=100&page=%d", apiURL(org), org, repo, env, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list branch policies: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to branch policy API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list branch policies for %s/%s: %s", repo, env, body)
+		}
+
+		var response struct {
+			TotalCount     int                  `json:"total_count"`
+			BranchPolicies []types.BranchPolicy `json:"branch_policies"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode branch policies: %w", err)
+		}
+
+		policies = append(policies, response.BranchPolicies...)
+		if len(response.BranchPolicies) == 0 || len(policies) >= response.TotalCount {
+			return policies, nil
+		}
+	}
+}
+
+// MapEnvironmentOptions converts an environment returned by GitHub into the config options that would create it.
+func MapEnvironmentOptions(environment types.Environment) types.DeploymentEnvOptions {
+	envOptions := types.DeploymentEnvOptions{
+		CreateDeploymentEnv:    true,
+		DeploymentBranchPolicy: environment.DeploymentBranchPolicy,
+	}
+	for _, rule := range environment.ProtectionRules {
+		switch rule.Type {
+		case "wait_timer":
+			waitTimer := rule.WaitTimer
+			envOptions.WaitTimer = &waitTimer
+		case "required_reviewers":
+			preventSelfReview := rule.PreventSelfReview
+			envOptions.PreventSelfReview = &preventSelfReview
+			for _, reviewer := range rule.Reviewers {
+				name := reviewer.Reviewer.Login
+				if name == "" {
+					name = reviewer.Reviewer.Slug
+				}
+				envOptions.Reviewers = append(envOptions.Reviewers, types.Reviewer{
+					Type: reviewer.Type,
+					ID:   reviewer.Reviewer.ID,
+					Name: name,
+				})
+			}
+		}
+	}
+	return envOptions
+}
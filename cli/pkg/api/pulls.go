@@ -0,0 +1,31 @@
+package api
+
+import (
+	"fmt"
+)
+
+// PullRequest is a pull request, as returned for a commit or on its own.
+type PullRequest struct {
+	Number   int    `json:"number"`
+	Title    string `json:"title"`
+	State    string `json:"state"` // open or closed
+	HTMLURL  string `json:"html_url"`
+	MergedAt string `json:"merged_at"`
+	User     struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Head struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+// GetPullRequest retrieves a single pull request.
+func GetPullRequest(org, repo string, number int) (*PullRequest, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", apiURL(org), org, repo, number)
+	var pull PullRequest
+	if err := getJSON(org, uri, &pull); err != nil {
+		return nil, fmt.Errorf("failed to fetch pull request #%d: %w", number, err)
+	}
+	return &pull, nil
+}
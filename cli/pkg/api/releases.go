@@ -18,13 +18,13 @@ type Release struct {
 
 // FetchReleases retrieves all releases for a given repository.
 func FetchReleases(org, repo string) ([]Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", org, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/releases", apiURL(org), org, repo)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch releases: %w", err)
 	}
 	// Set the Authorization header using req.Header.Set()
-	req.Header.Set("Authorization", "bearer "+SetHeader())
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
 
 	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// ListOrgRepos retrieves all repositories of an organization, following pagination.
+func ListOrgRepos(org string) ([]types.Repository, error) {
+	var repos []types.Repository
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/orgs/%s/repos?per_page=100&page=%d", apiURL(org), org, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to repository API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list repositories for %s: %s", org, body)
+		}
+
+		var pageRepos []types.Repository
+		err = json.NewDecoder(resp.Body).Decode(&pageRepos)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode repositories: %w", err)
+		}
+
+		repos = append(repos, pageRepos...)
+		if len(pageRepos) < 100 {
+			return repos, nil
+		}
+	}
+}
+
+// ListTeamRepos retrieves the repositories a team has access to, following pagination.
+func ListTeamRepos(org, team string) ([]types.Repository, error) {
+	var repos []types.Repository
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/orgs/%s/teams/%s/repos?per_page=100&page=%d", apiURL(org), org, team, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list team repositories: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to team API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list repositories for team %s: %s", team, body)
+		}
+
+		var pageRepos []types.Repository
+		err = json.NewDecoder(resp.Body).Decode(&pageRepos)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode team repositories: %w", err)
+		}
+
+		repos = append(repos, pageRepos...)
+		if len(pageRepos) < 100 {
+			return repos, nil
+		}
+	}
+}
+
+// ListCustomPropertyValues retrieves the custom property values of every repository in an org,
+// keyed by repository name and then property name.
+func ListCustomPropertyValues(org string) (map[string]map[string]string, error) {
+	values := make(map[string]map[string]string)
+	for page := 1; ; page++ {
+		uri := fmt.Sprintf("%s/orgs/%s/properties/values?per_page=100&page=%d", apiURL(org), org, page)
+		req, err := http.NewRequest("GET", uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list custom property values: %w", err)
+		}
+		req.Header.Add("Authorization", "bearer "+orgToken(org))
+		req.Header.Add("Accept", "application/vnd.github+json")
+		req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request to custom properties API: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list custom property values for %s: %s", org, body)
+		}
+
+		var pageValues []struct {
+			RepositoryName string `json:"repository_name"`
+			Properties     []struct {
+				PropertyName string      `json:"property_name"`
+				Value        interface{} `json:"value"`
+			} `json:"properties"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&pageValues)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode custom property values: %w", err)
+		}
+
+		for _, repo := range pageValues {
+			properties := make(map[string]string)
+			for _, property := range repo.Properties {
+				// multi_select properties come back as a list, they are joined so they can still be matched
+				switch value := property.Value.(type) {
+				case string:
+					properties[property.PropertyName] = value
+				case []interface{}:
+					var parts []string
+					for _, part := range value {
+						parts = append(parts, fmt.Sprint(part))
+					}
+					properties[property.PropertyName] = strings.Join(parts, ",")
+				case nil:
+				default:
+					properties[property.PropertyName] = fmt.Sprint(value)
+				}
+			}
+			values[repo.RepositoryName] = properties
+		}
+		if len(pageValues) < 100 {
+			return values, nil
+		}
+	}
+}
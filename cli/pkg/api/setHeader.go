@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 func SetHeader() string {
@@ -14,3 +15,60 @@ func SetHeader() string {
 	}
 	return GITHUB_TOKEN
 }
+
+// DefaultAPIHost is the API host used for organizations without an apiHost in the config
+const DefaultAPIHost = "https://api.github.com"
+
+// orgSettings holds the API host and token environment variable configured for an organization
+type orgSettings struct {
+	apiHost  string
+	tokenEnv string
+}
+
+var orgs = make(map[string]orgSettings)
+
+// RegisterOrg sets the API host (e.g. https://github.example.com/api/v3 for GitHub Enterprise Server)
+// and the environment variable holding the token used for every request to an organization.
+// An empty API host means api.github.com and an empty token variable means GITHUB_TOKEN, which is
+// only ever sent to api.github.com.
+func RegisterOrg(org, apiHost, tokenEnv string) {
+	orgs[org] = orgSettings{apiHost: strings.TrimSuffix(apiHost, "/"), tokenEnv: tokenEnv}
+}
+
+// apiURL returns the API host requests for an organization are sent to
+func apiURL(org string) string {
+	if settings, ok := orgs[org]; ok && settings.apiHost != "" {
+		return settings.apiHost
+	}
+	return DefaultAPIHost
+}
+
+// Token returns the token for an organization. There is no fallback: an organization with a
+// tokenEnv only uses that variable, and one on another API host must have its own tokenEnv.
+func Token(org string) (string, error) {
+	settings := orgs[org]
+	if settings.tokenEnv != "" {
+		if token := os.Getenv(settings.tokenEnv); token != "" {
+			return token, nil
+		}
+		return "", fmt.Errorf("%s environment variable not set for %s", settings.tokenEnv, org)
+	}
+	if host := apiURL(org); host != DefaultAPIHost {
+		return "", fmt.Errorf("%s uses %s, set tokenEnv to the environment variable holding its token", org, host)
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token, nil
+	}
+	return "", fmt.Errorf("GITHUB_TOKEN environment variable not set")
+}
+
+// orgToken returns the token for an organization and stops with the error on stderr when there is none,
+// so that nothing is ever written to stdout in the middle of a command's output
+func orgToken(org string) string {
+	token, err := Token(org)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return token
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CurrentUser returns the login of the user the organization's token belongs to.
+func CurrentUser(org string) (string, error) {
+	req, err := http.NewRequest("GET", apiURL(org)+"/user", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch current user: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+orgToken(org))
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch current user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch current user: status: %s", resp.Status)
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return "", fmt.Errorf("failed to decode current user: %w", err)
+	}
+	return user.Login, nil
+}
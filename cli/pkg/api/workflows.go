@@ -1,9 +1,13 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 // Workflow represents a GitHub Actions workflow.
@@ -20,6 +24,8 @@ type Workflow struct {
 // WorkflowRun represents a GitHub Actions workflow run.
 type WorkflowRun struct {
 	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	DisplayTitle string `json:"display_title"`
 	Status       string `json:"status"`
 	Conclusion   string `json:"conclusion"`
 	RunNumber    int    `json:"run_number"`
@@ -30,11 +36,24 @@ type WorkflowRun struct {
 	CreatedAt    string `json:"created_at"`
 	UpdatedAt    string `json:"updated_at"`
 	RunStartedAt string `json:"run_started_at"`
+	Actor        struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+}
+
+// PendingDeployment is an environment a workflow run is waiting on for review.
+type PendingDeployment struct {
+	Environment struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"environment"`
+	WaitTimer             int  `json:"wait_timer"`
+	CurrentUserCanApprove bool `json:"current_user_can_approve"`
 }
 
 // FetchWorkflows retrieves all workflows for a given repository.
 func FetchWorkflows(org, repo string) ([]Workflow, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/workflows", org, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/actions/workflows", apiURL(org), org, repo)
 
 	// Create a new request using http.NewRequest() and set the Authorization header
 	req, err := http.NewRequest("GET", url, nil)
@@ -42,7 +61,7 @@ func FetchWorkflows(org, repo string) ([]Workflow, error) {
 		return nil, fmt.Errorf("failed to fetch workflows: %w", err)
 	}
 	// Set the Authorization header using req.Header.Set()
-	req.Header.Add("Authorization", "bearer "+SetHeader())
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
 
 	// Send the request using http.DefaultClient.Do() and check the response
 	resp, err := http.DefaultClient.Do(req)
@@ -67,8 +86,26 @@ func FetchWorkflows(org, repo string) ([]Workflow, error) {
 
 // FetchWorkflowRuns retrieves all runs for a specific workflow in a repository.
 func FetchWorkflowRuns(org, repo string, workflowID int) ([]WorkflowRun, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/actions/workflows/%d/runs", org, repo, workflowID)
-	resp, err := http.Get(url)
+	return fetchWorkflowRuns(org, fmt.Sprintf("%s/repos/%s/%s/actions/workflows/%d/runs", apiURL(org), org, repo, workflowID))
+}
+
+// FetchDispatchedRuns retrieves the workflow_dispatch runs of a workflow (ID or file name) created since a time.
+func FetchDispatchedRuns(org, repo, workflow string, since time.Time) ([]WorkflowRun, error) {
+	query := url.Values{}
+	query.Set("event", "workflow_dispatch")
+	query.Set("created", ">="+since.UTC().Format(time.RFC3339))
+	return fetchWorkflowRuns(org, fmt.Sprintf("%s/repos/%s/%s/actions/workflows/%s/runs?%s", apiURL(org), org, repo, url.PathEscape(workflow), query.Encode()))
+}
+
+func fetchWorkflowRuns(org, uri string) ([]WorkflowRun, error) {
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow runs: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch workflow runs: %w", err)
 	}
@@ -87,3 +124,131 @@ func FetchWorkflowRuns(org, repo string, workflowID int) ([]WorkflowRun, error)
 
 	return response.WorkflowRuns, nil
 }
+
+// GetWorkflowRun retrieves a single workflow run.
+func GetWorkflowRun(org, repo string, runID int) (*WorkflowRun, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d", apiURL(org), org, repo, runID)
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow run: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workflow run: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch workflow run %d: HTTP %d", runID, resp.StatusCode)
+	}
+
+	var run WorkflowRun
+	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+		return nil, fmt.Errorf("failed to decode workflow run: %w", err)
+	}
+	return &run, nil
+}
+
+// DispatchWorkflow triggers a workflow_dispatch event for a workflow (ID or file name) on a branch or tag.
+func DispatchWorkflow(org, repo, workflow, ref string, inputs map[string]string) error {
+	body, err := json.Marshal(struct {
+		Ref    string            `json:"ref"`
+		Inputs map[string]string `json:"inputs,omitempty"`
+	}{Ref: ref, Inputs: inputs})
+	if err != nil {
+		return fmt.Errorf("failed to encode workflow inputs: %w", err)
+	}
+
+	uri := fmt.Sprintf("%s/repos/%s/%s/actions/workflows/%s/dispatches", apiURL(org), org, repo, url.PathEscape(workflow))
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create dispatch request: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to workflow dispatch API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to dispatch %s on %s: %s", workflow, ref, body)
+	}
+	return nil
+}
+
+// FetchWaitingRuns retrieves the workflow runs of a repository waiting on an environment review.
+func FetchWaitingRuns(org, repo string) ([]WorkflowRun, error) {
+	return fetchWorkflowRuns(org, fmt.Sprintf("%s/repos/%s/%s/actions/runs?status=waiting&per_page=100", apiURL(org), org, repo))
+}
+
+// FetchPendingDeployments retrieves the environments a workflow run is waiting on.
+func FetchPendingDeployments(org, repo string, runID int) ([]PendingDeployment, error) {
+	uri := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d/pending_deployments", apiURL(org), org, repo, runID)
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending deployments: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending deployments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch pending deployments of run %d: HTTP %d", runID, resp.StatusCode)
+	}
+
+	var pending []PendingDeployment
+	if err := json.NewDecoder(resp.Body).Decode(&pending); err != nil {
+		return nil, fmt.Errorf("failed to decode pending deployments: %w", err)
+	}
+	return pending, nil
+}
+
+// ReviewPendingDeployments approves or rejects the deployments of a run to the given environment IDs.
+// state is "approved" or "rejected".
+func ReviewPendingDeployments(org, repo string, runID int, environmentIDs []int, state, comment string) error {
+	body, err := json.Marshal(struct {
+		EnvironmentIDs []int  `json:"environment_ids"`
+		State          string `json:"state"`
+		Comment        string `json:"comment"`
+	}{EnvironmentIDs: environmentIDs, State: state, Comment: comment})
+	if err != nil {
+		return fmt.Errorf("failed to encode review: %w", err)
+	}
+
+	uri := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d/pending_deployments", apiURL(org), org, repo, runID)
+	req, err := http.NewRequest("POST", uri, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create review request: %w", err)
+	}
+	req.Header.Add("Authorization", "bearer "+orgToken(org))
+	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("X-GitHub-Api-version", "2022-11-28")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to pending deployments API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to review run %d: %s", runID, body)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Load reads the configuration into a types.Config, resolving defaults and environment templates.
+// path can be a file, a directory or a glob pattern, and files can pull in others with `include:`;
+// all of them are merged into one config, see mergeDocument.
+// Both the single organization layout and `orgs:` end up in config.Orgs.
+// Unknown keys and invalid names are returned as ValidationErrors with the line and column they are on.
+// The file is decoded with yaml.v3 directly rather than through viper so that the case of
+// repository, environment and variable names, and of keys inside structured values, is kept.
+// Config keys are case-sensitive too, unlike with viper, and mis-cased keys are reported with a hint.
+func Load(path string) (types.Config, error) {
+	var config types.Config
+
+	root, files, err := loadDocuments(path)
+	if err != nil {
+		return config, err
+	}
+
+	var errs ValidationErrors
+	checkKeys(files, root, reflect.TypeOf(config), &errs)
+	if err := resolveInheritance(root); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	validateResolved(files, root, &errs)
+	if len(errs) > 0 {
+		return config, errs
+	}
+	if err := root.Decode(&config); err != nil {
+		return config, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := normalizeOrgs(&config); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
+
+// normalizeOrgs moves the single organization layout (org, repos and repoSelectors) into Orgs,
+// so both layouts can be used, even together in one file. Top-level previews are given to every
+// organization without its own.
+func normalizeOrgs(config *types.Config) error {
+	if err := moveLegacyOrg(config); err != nil {
+		return err
+	}
+	if config.Previews != nil {
+		for name, org := range config.Orgs {
+			if org.Previews == nil {
+				org.Previews = config.Previews
+				config.Orgs[name] = org
+			}
+		}
+		config.Previews = nil
+	}
+	return nil
+}
+
+// moveLegacyOrg moves the top-level org, repos and repoSelectors into orgs
+func moveLegacyOrg(config *types.Config) error {
+	if config.Org == "" {
+		if len(config.Repos) > 0 || len(config.RepoSelectors) > 0 {
+			return fmt.Errorf("repos and repoSelectors need an org")
+		}
+		return nil
+	}
+
+	if config.Orgs == nil {
+		config.Orgs = make(map[string]types.OrgConfig)
+	}
+	org := config.Orgs[config.Org]
+	if org.Repos == nil {
+		org.Repos = make(map[string]map[string]types.DeploymentEnvOptions)
+	}
+	for repoName, environments := range config.Repos {
+		if _, exists := org.Repos[repoName]; exists {
+			return fmt.Errorf("repository %s/%s is configured both under repos and under orgs", config.Org, repoName)
+		}
+		org.Repos[repoName] = environments
+	}
+	org.RepoSelectors = append(config.RepoSelectors, org.RepoSelectors...)
+	config.Orgs[config.Org] = org
+
+	config.Org, config.Repos, config.RepoSelectors = "", nil, nil
+	return nil
+}
+
+// OrgNames returns the names of the configured organizations in a stable order
+func OrgNames(config types.Config) []string {
+	names := make([]string, 0, len(config.Orgs))
+	for name := range config.Orgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
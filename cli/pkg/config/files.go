@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// sourceFiles remembers which file every node of a merged config came from, for error messages
+type sourceFiles struct {
+	nodes   map[*yaml.Node]string
+	primary string
+}
+
+func (f sourceFiles) of(node *yaml.Node) string {
+	if file, ok := f.nodes[node]; ok {
+		return file
+	}
+	return f.primary
+}
+
+func (f sourceFiles) record(file string, node *yaml.Node) {
+	f.nodes[node] = file
+	for _, child := range node.Content {
+		f.record(file, child)
+	}
+}
+
+// configFiles expands a --config value into the files to load. It can be a file, a directory,
+// whose *.yaml and *.yml files are loaded recursively in lexical order, or a glob pattern.
+func configFiles(path string) ([]string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config pattern %s: %w", path, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no config files match %s", path)
+		}
+		sort.Strings(matches)
+		return matches, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(file); !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory %s: %w", path, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no config files found in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadDocuments reads every file path expands to, following `include:` lists, and merges them into one root node
+func loadDocuments(path string) (*yaml.Node, sourceFiles, error) {
+	files := sourceFiles{nodes: make(map[*yaml.Node]string), primary: path}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	loaded := make(map[string]bool)
+
+	var load func(path string) error
+	load = func(path string) error {
+		paths, err := configFiles(path)
+		if err != nil {
+			return err
+		}
+		for _, file := range paths {
+			absolute, _ := filepath.Abs(file)
+			if loaded[absolute] {
+				continue
+			}
+			loaded[absolute] = true
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read config file %s: %w", file, err)
+			}
+			var document yaml.Node
+			if err := yaml.Unmarshal(data, &document); err != nil {
+				return fmt.Errorf("failed to parse config file %s: %w", file, err)
+			}
+			if len(document.Content) == 0 {
+				continue
+			}
+			node := resolveAlias(document.Content[0])
+			if node.Kind != yaml.MappingNode {
+				return fmt.Errorf("%s:%d:%d: expected a map at the top of the config", file, node.Line, node.Column)
+			}
+			files.record(file, node)
+
+			includes := mappingValue(node, "include")
+			removeKey(node, "include")
+			if err := mergeDocument(files, root, node, nil); err != nil {
+				return err
+			}
+
+			if includes != nil {
+				var patterns []string
+				if err := includes.Decode(&patterns); err != nil {
+					return fmt.Errorf("%s:%d:%d: include must be a list of files, directories or patterns", file, includes.Line, includes.Column)
+				}
+				for _, pattern := range patterns {
+					if !filepath.IsAbs(pattern) {
+						pattern = filepath.Join(filepath.Dir(file), pattern)
+					}
+					if err := load(pattern); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := load(path); err != nil {
+		return nil, files, err
+	}
+	return root, files, nil
+}
+
+// mergeDocument merges the top-level node of one file into the merged config.
+// Maps are merged key by key and lists are concatenated, but an environment, template or defaults
+// block can only be defined in one file and scalars like org must agree everywhere.
+func mergeDocument(files sourceFiles, into, from *yaml.Node, path []string) error {
+	for i := 0; i+1 < len(from.Content); i += 2 {
+		key, value := from.Content[i], resolveAlias(from.Content[i+1])
+		keyPath := append(append([]string(nil), path...), key.Value)
+
+		existingIndex := -1
+		for j := 0; j+1 < len(into.Content); j += 2 {
+			if into.Content[j].Value == key.Value {
+				existingIndex = j
+				break
+			}
+		}
+		if existingIndex < 0 {
+			into.Content = append(into.Content, key, value)
+			continue
+		}
+
+		existingKey, existing := into.Content[existingIndex], resolveAlias(into.Content[existingIndex+1])
+		conflict := fmt.Errorf("%s:%d:%d: %s is already defined at %s:%d:%d",
+			files.of(key), key.Line, key.Column, strings.Join(keyPath, "."), files.of(existingKey), existingKey.Line, existingKey.Column)
+
+		switch {
+		case isDefinition(keyPath):
+			return conflict
+		case existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if err := mergeDocument(files, existing, value, keyPath); err != nil {
+				return err
+			}
+		case existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			existing.Content = append(existing.Content, value.Content...)
+		case existing.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode && existing.Value == value.Value:
+		default:
+			return conflict
+		}
+	}
+	return nil
+}
+
+// isDefinition reports whether a key path names something that must be defined in a single file:
+// an environment of a repository, an environment template or a defaults block
+func isDefinition(path []string) bool {
+	if len(path) >= 2 && path[0] == "orgs" {
+		path = path[2:]
+	}
+	switch {
+	case len(path) == 1 && path[0] == "defaults":
+		return true
+	case len(path) == 2 && path[0] == "environmentTemplates":
+		return true
+	case len(path) == 3 && path[0] == "repos":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"path"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Filter narrows a config down to some repositories, environments and secret or variable names.
+// Every pattern is a case-insensitive glob; an empty list matches everything.
+// Exclude patterns are matched against "repo", "repo/env" and "repo/env/name".
+type Filter struct {
+	Repos   []string
+	Envs    []string
+	Names   []string
+	Exclude []string
+}
+
+// Empty reports whether the filter lets everything through
+func (f Filter) Empty() bool {
+	return len(f.Repos) == 0 && len(f.Envs) == 0 && len(f.Names) == 0 && len(f.Exclude) == 0
+}
+
+// MatchRepo reports whether a repository is selected. Patterns containing a slash are matched against org/repo.
+func (f Filter) MatchRepo(org, repo string) bool {
+	if excluded(f.Exclude, repo) {
+		return false
+	}
+	if len(f.Repos) == 0 {
+		return true
+	}
+	for _, pattern := range f.Repos {
+		if strings.Contains(pattern, "/") && globMatch(pattern, org+"/"+repo) {
+			return true
+		}
+		if globMatch(pattern, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchEnv reports whether an environment of a repository is selected
+func (f Filter) MatchEnv(repo, env string) bool {
+	return !excluded(f.Exclude, repo+"/"+env) && matchAny(f.Envs, env)
+}
+
+// MatchName reports whether a secret or variable of an environment is selected
+func (f Filter) MatchName(repo, env, name string) bool {
+	return !excluded(f.Exclude, repo+"/"+env+"/"+name) && matchAny(f.Names, name)
+}
+
+// Apply returns a copy of config with only the selected repositories, environments, secrets and variables.
+// With name patterns, environments left without secrets or variables are dropped, and so are
+// repositories left without environments.
+func (f Filter) Apply(config types.Config) types.Config {
+	if f.Empty() {
+		return config
+	}
+
+	filtered := config
+	filtered.Orgs = make(map[string]types.OrgConfig, len(config.Orgs))
+	for orgName, org := range config.Orgs {
+		repos := make(map[string]map[string]types.DeploymentEnvOptions)
+		for repoName, environments := range org.Repos {
+			if !f.MatchRepo(orgName, repoName) {
+				continue
+			}
+			selected := make(map[string]types.DeploymentEnvOptions)
+			for envName, envOptions := range environments {
+				if !f.MatchEnv(repoName, envName) {
+					continue
+				}
+				envOptions.Variables = f.filterValues(repoName, envName, envOptions.Variables)
+				envOptions.Secrets = f.filterValues(repoName, envName, envOptions.Secrets)
+				if len(f.Names) > 0 && len(envOptions.Variables) == 0 && len(envOptions.Secrets) == 0 {
+					continue
+				}
+				selected[envName] = envOptions
+			}
+			if len(selected) > 0 {
+				repos[repoName] = selected
+			}
+		}
+		org.Repos = repos
+		filtered.Orgs[orgName] = org
+	}
+	return filtered
+}
+
+func (f Filter) filterValues(repo, env string, values types.Values) types.Values {
+	if values == nil {
+		return nil
+	}
+	selected := make(types.Values)
+	for name, value := range values {
+		if f.MatchName(repo, env, name) {
+			selected[name] = value
+		}
+	}
+	return selected
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if globMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func excluded(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if globMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch matches case-insensitively, as GitHub names are case-insensitive
+func globMatch(pattern, value string) bool {
+	ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return ok
+}
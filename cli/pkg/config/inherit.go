@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveInheritance applies `defaults:` and `environmentTemplates:` to every environment in `repos:`
+// and `repoSelectors:`, at the top level and in every organization under `orgs:`.
+// Each environment is built from the defaults, then every template it `extends:` in order, then its own
+// settings, with later layers overriding earlier ones. Maps such as variables and secrets are deep-merged
+// and a null value removes an inherited entry. The environment of `previews:` is resolved the same way.
+// An organization's defaults are layered over the top-level ones and its templates shadow top-level
+// templates of the same name. The defaults and templates are removed from the result.
+func resolveInheritance(root *yaml.Node) error {
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a map at the top of the config", root.Line)
+	}
+
+	defaults := mappingValue(root, "defaults")
+	templates := mappingValue(root, "environmentTemplates")
+	if err := resolveScope("", root, defaults, templates); err != nil {
+		return err
+	}
+
+	if orgsNode := mappingValue(root, "orgs"); orgsNode != nil && orgsNode.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(orgsNode.Content); i += 2 {
+			orgName, orgNode := orgsNode.Content[i].Value, resolveAlias(orgsNode.Content[i+1])
+			if orgNode.Kind != yaml.MappingNode {
+				continue
+			}
+			orgDefaults := defaults
+			if own := mappingValue(orgNode, "defaults"); own != nil {
+				if defaults != nil {
+					orgDefaults = mergeNodes(defaults, own)
+				} else {
+					orgDefaults = own
+				}
+			}
+			if err := resolveScope(orgName+"/", orgNode, orgDefaults, mappingValue(orgNode, "environmentTemplates"), templates); err != nil {
+				return err
+			}
+			removeKey(orgNode, "defaults")
+			removeKey(orgNode, "environmentTemplates")
+		}
+	}
+
+	removeKey(root, "defaults")
+	removeKey(root, "environmentTemplates")
+	return nil
+}
+
+// resolveScope resolves the repos and repoSelectors of one mapping node (the top level or an organization).
+// Templates are looked up in the given template nodes in order.
+func resolveScope(prefix string, scope, defaults *yaml.Node, templates ...*yaml.Node) error {
+	lookup := func(name string) *yaml.Node {
+		for _, node := range templates {
+			if template := mappingValue(node, name); template != nil {
+				return template
+			}
+		}
+		return nil
+	}
+
+	resolved := make(map[string]*yaml.Node)
+	var resolveTemplate func(name string, seen []string) (*yaml.Node, error)
+	resolveTemplate = func(name string, seen []string) (*yaml.Node, error) {
+		if node, ok := resolved[name]; ok {
+			return node, nil
+		}
+		for _, s := range seen {
+			if s == name {
+				return nil, fmt.Errorf("environment template %q extends itself through %v", name, append(seen, name))
+			}
+		}
+		template := lookup(name)
+		if template == nil {
+			return nil, fmt.Errorf("unknown environment template %q", name)
+		}
+		node, err := applyExtends(template, nil, func(parent string) (*yaml.Node, error) {
+			return resolveTemplate(parent, append(seen, name))
+		})
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", template.Line, err)
+		}
+		resolved[name] = node
+		return node, nil
+	}
+
+	resolveEnvironments := func(label string, environments *yaml.Node) error {
+		if environments == nil || environments.Kind != yaml.MappingNode {
+			return nil
+		}
+		for j := 0; j+1 < len(environments.Content); j += 2 {
+			envName, envNode := environments.Content[j].Value, resolveAlias(environments.Content[j+1])
+			if envNode.Kind != yaml.MappingNode {
+				continue
+			}
+			node, err := applyExtends(envNode, defaults, func(parent string) (*yaml.Node, error) {
+				return resolveTemplate(parent, nil)
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %s%s/%s: %w", envNode.Line, prefix, label, envName, err)
+			}
+			environments.Content[j+1] = node
+		}
+		return nil
+	}
+
+	if repos := mappingValue(scope, "repos"); repos != nil && repos.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(repos.Content); i += 2 {
+			if err := resolveEnvironments(repos.Content[i].Value, resolveAlias(repos.Content[i+1])); err != nil {
+				return err
+			}
+		}
+	}
+	if selectors := mappingValue(scope, "repoSelectors"); selectors != nil && selectors.Kind == yaml.SequenceNode {
+		for i, selector := range selectors.Content {
+			if err := resolveEnvironments(fmt.Sprintf("repoSelectors[%d]", i), mappingValue(selector, "environments")); err != nil {
+				return err
+			}
+		}
+	}
+	if previews := mappingValue(scope, "previews"); previews != nil && previews.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(previews.Content); i += 2 {
+			envNode := resolveAlias(previews.Content[i+1])
+			if previews.Content[i].Value != "environment" || envNode.Kind != yaml.MappingNode {
+				continue
+			}
+			node, err := applyExtends(envNode, defaults, func(parent string) (*yaml.Node, error) {
+				return resolveTemplate(parent, nil)
+			})
+			if err != nil {
+				return fmt.Errorf("line %d: %spreviews/environment: %w", envNode.Line, prefix, err)
+			}
+			previews.Content[i+1] = node
+		}
+	}
+	return nil
+}
+
+// applyExtends layers base, then the templates listed in a node's `extends:`, then the node itself
+func applyExtends(node, base *yaml.Node, template func(name string) (*yaml.Node, error)) (*yaml.Node, error) {
+	var names []string
+	switch extends := mappingValue(node, "extends"); {
+	case extends == nil:
+	case extends.Kind == yaml.ScalarNode:
+		names = []string{extends.Value}
+	case extends.Kind == yaml.SequenceNode:
+		for _, item := range extends.Content {
+			names = append(names, item.Value)
+		}
+	default:
+		return nil, fmt.Errorf("extends must be a template name or a list of template names")
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if base != nil {
+		merged = mergeNodes(merged, base)
+	}
+	for _, name := range names {
+		parent, err := template(name)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, parent)
+	}
+
+	own := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: node.Line, Column: node.Column}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "extends" {
+			own.Content = append(own.Content, node.Content[i], node.Content[i+1])
+		}
+	}
+	return mergeNodes(merged, own), nil
+}
+
+// mergeNodes deep-merges two mapping nodes into a new node, with override taking precedence.
+// Null values in override remove the key.
+func mergeNodes(base, override *yaml.Node) *yaml.Node {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: override.Line, Column: override.Column}
+	if override.Line == 0 {
+		merged.Line, merged.Column = base.Line, base.Column
+	}
+
+	for i := 0; i+1 < len(base.Content); i += 2 {
+		key, value := base.Content[i], base.Content[i+1]
+		if mappingValue(override, key.Value) == nil {
+			merged.Content = append(merged.Content, key, value)
+		}
+	}
+	for i := 0; i+1 < len(override.Content); i += 2 {
+		key, value := override.Content[i], resolveAlias(override.Content[i+1])
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+			continue
+		}
+		if inherited := mappingValue(base, key.Value); inherited != nil && inherited.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+			value = mergeNodes(inherited, value)
+		}
+		merged.Content = append(merged.Content, key, value)
+	}
+	return merged
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// removeKey deletes key from a mapping node
+func removeKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.AliasNode {
+		return node.Alias
+	}
+	return node
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Scope is what `${{ ... }}` expressions in variable and secret values can refer to:
+//
+//	org, repo.name, env.name  the organization, repository and environment being configured
+//	vars.NAME                 another variable of the same environment (itself interpolated)
+//	process.env.NAME          an environment variable of the AutoGit process
+//
+// and the functions lower(x), upper(x) and default(x, fallback, ...), which returns its first
+// defined, non-empty argument. Strings are written in single quotes and `$${{` gives a literal `${{`.
+type Scope struct {
+	Org  string
+	Repo string
+	Env  string
+	Vars types.Values
+
+	rendered map[string]string
+	visiting map[string]bool
+}
+
+// NewScope creates the scope for the variables of one environment
+func NewScope(org, repo, env string, vars types.Values) *Scope {
+	return &Scope{Org: org, Repo: repo, Env: env, Vars: vars}
+}
+
+// Interpolate replaces every `${{ ... }}` expression in value
+func (s *Scope) Interpolate(value string) (string, error) {
+	var out strings.Builder
+	for {
+		start := strings.Index(value, "${{")
+		if start < 0 {
+			out.WriteString(value)
+			return out.String(), nil
+		}
+		if start > 0 && value[start-1] == '$' {
+			out.WriteString(value[:start-1] + "${{")
+			value = value[start+3:]
+			continue
+		}
+		end := strings.Index(value[start:], "}}")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated expression %q", value[start:])
+		}
+		expression := value[start+3 : start+end]
+
+		result, defined, err := s.evaluate(expression)
+		if err != nil {
+			return "", err
+		}
+		if !defined {
+			return "", fmt.Errorf("undefined reference in ${{%s}}", expression)
+		}
+		out.WriteString(value[:start] + result)
+		value = value[start+end+2:]
+	}
+}
+
+// variable returns the interpolated value of another variable of the environment
+func (s *Scope) variable(name string) (string, bool, error) {
+	key := ""
+	for candidate := range s.Vars {
+		// GitHub variable names are case-insensitive
+		if strings.EqualFold(candidate, name) {
+			key = candidate
+			break
+		}
+	}
+	if key == "" {
+		return "", false, nil
+	}
+
+	if s.rendered == nil {
+		s.rendered = make(map[string]string)
+		s.visiting = make(map[string]bool)
+	}
+	if value, ok := s.rendered[key]; ok {
+		return value, true, nil
+	}
+	if s.visiting[key] {
+		return "", false, fmt.Errorf("variable %s refers to itself", key)
+	}
+	s.visiting[key] = true
+	defer delete(s.visiting, key)
+
+	value, err := s.Interpolate(s.Vars[key])
+	if err != nil {
+		return "", false, fmt.Errorf("in variable %s: %w", key, err)
+	}
+	s.rendered[key] = value
+	return value, true, nil
+}
+
+// evaluate parses and evaluates one expression, reporting whether its result is defined
+func (s *Scope) evaluate(expression string) (string, bool, error) {
+	p := &parser{input: expression}
+	result, defined, err := p.expression(s)
+	if err != nil {
+		return "", false, fmt.Errorf("in ${{%s}}: %w", expression, err)
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return "", false, fmt.Errorf("in ${{%s}}: unexpected %q", expression, p.input[p.pos:])
+	}
+	return result, defined, nil
+}
+
+// resolve looks up a dotted reference such as repo.name
+func (s *Scope) resolve(reference string) (string, bool, error) {
+	switch {
+	case reference == "org":
+		return s.Org, true, nil
+	case reference == "repo.name":
+		return s.Repo, true, nil
+	case reference == "env.name":
+		return s.Env, true, nil
+	case strings.HasPrefix(reference, "vars."):
+		return s.variable(strings.TrimPrefix(reference, "vars."))
+	case strings.HasPrefix(reference, "process.env."):
+		value, ok := os.LookupEnv(strings.TrimPrefix(reference, "process.env."))
+		return value, ok, nil
+	}
+	return "", false, fmt.Errorf("unknown reference %s", reference)
+}
+
+// parser is a small recursive descent parser for: call | reference | 'string'
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) expression(s *Scope) (string, bool, error) {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return "", false, fmt.Errorf("empty expression")
+	}
+
+	if p.input[p.pos] == '\'' {
+		end := strings.IndexByte(p.input[p.pos+1:], '\'')
+		if end < 0 {
+			return "", false, fmt.Errorf("unterminated string")
+		}
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, true, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := rune(p.input[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != '.' && c != '-' {
+			break
+		}
+		p.pos++
+	}
+	name := p.input[start:p.pos]
+	if name == "" {
+		return "", false, fmt.Errorf("unexpected %q", p.input[p.pos:])
+	}
+
+	p.skipSpace()
+	if p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		return s.resolve(name)
+	}
+
+	p.pos++
+	var args []string
+	var defined []bool
+	for {
+		p.skipSpace()
+		if p.pos < len(p.input) && p.input[p.pos] == ')' {
+			p.pos++
+			break
+		}
+		// Undefined arguments are passed on so default() can skip them
+		value, ok, err := p.expression(s)
+		if err != nil {
+			return "", false, err
+		}
+		args = append(args, value)
+		defined = append(defined, ok)
+		p.skipSpace()
+		if p.pos < len(p.input) && p.input[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos < len(p.input) && p.input[p.pos] == ')' {
+			p.pos++
+			break
+		}
+		return "", false, fmt.Errorf("expected , or ) in call to %s", name)
+	}
+	return call(name, args, defined)
+}
+
+// call applies one of the supported functions
+func call(name string, args []string, defined []bool) (string, bool, error) {
+	switch name {
+	case "lower", "upper":
+		if len(args) != 1 {
+			return "", false, fmt.Errorf("%s takes one argument", name)
+		}
+		if !defined[0] {
+			return "", false, nil
+		}
+		if name == "lower" {
+			return strings.ToLower(args[0]), true, nil
+		}
+		return strings.ToUpper(args[0]), true, nil
+	case "default":
+		if len(args) < 2 {
+			return "", false, fmt.Errorf("default takes at least two arguments")
+		}
+		for i, arg := range args {
+			if defined[i] && arg != "" {
+				return arg, true, nil
+			}
+		}
+		return "", true, nil
+	}
+	return "", false, fmt.Errorf("unknown function %s", name)
+}
+
+// InterpolateOrg renders the variable and secret values of every environment of an organization
+func InterpolateOrg(orgName string, org *types.OrgConfig) error {
+	for repoName, environments := range org.Repos {
+		for envName, envOptions := range environments {
+			if err := InterpolateEnvironment(orgName, repoName, envName, &envOptions); err != nil {
+				return err
+			}
+			environments[envName] = envOptions
+		}
+	}
+	return nil
+}
+
+// InterpolateEnvironment renders the variable and secret values of one environment
+func InterpolateEnvironment(orgName, repoName, envName string, envOptions *types.DeploymentEnvOptions) error {
+	scope := NewScope(orgName, repoName, envName, envOptions.Variables)
+
+	variables := make(types.Values, len(envOptions.Variables))
+	for name := range envOptions.Variables {
+		value, _, err := scope.variable(name)
+		if err != nil {
+			return fmt.Errorf("%s/%s/%s: %w", orgName, repoName, envName, err)
+		}
+		variables[name] = value
+	}
+
+	secrets := make(types.Values, len(envOptions.Secrets))
+	for name, raw := range envOptions.Secrets {
+		value, err := scope.Interpolate(raw)
+		if err != nil {
+			return fmt.Errorf("%s/%s/%s: secret %s: %w", orgName, repoName, envName, name, err)
+		}
+		secrets[name] = value
+	}
+
+	if envOptions.Variables != nil {
+		envOptions.Variables = variables
+	}
+	if envOptions.Secrets != nil {
+		envOptions.Secrets = secrets
+	}
+	return nil
+}
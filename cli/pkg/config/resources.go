@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Resource is a kind of resource create makes from the config
+type Resource struct {
+	Name    string
+	Aliases []string
+	Named   bool // secrets and variables, the only resources --name selects
+}
+
+// Resources lists the resources create knows about, in the order they are created.
+// Environments come first as secrets, variables and branch policies live inside them.
+var Resources = []Resource{
+	{Name: "env", Aliases: []string{"deployment-env", "environments"}},
+	{Name: "secrets", Aliases: []string{"secret"}, Named: true},
+	{Name: "variables", Aliases: []string{"variable", "vars"}, Named: true},
+	{Name: "branch-policies", Aliases: []string{"branch-policy"}},
+}
+
+// SelectResources turns a --type value into resource names, in the order of Resources.
+// ALL selects every resource and secrets-variables is kept for existing scripts.
+// With name patterns in the filter only secrets and variables are selected.
+func SelectResources(typeFlag string, filter Filter) ([]string, error) {
+	var names []string
+	for _, resource := range Resources {
+		names = append(names, resource.Name)
+	}
+	if strings.TrimSpace(typeFlag) == "" {
+		return nil, fmt.Errorf("--type flag is required. Options: ALL or a comma-separated list of %s", strings.Join(names, ", "))
+	}
+
+	selected := make(map[string]bool)
+	for _, typeName := range strings.Split(typeFlag, ",") {
+		typeName = strings.TrimSpace(typeName)
+		switch {
+		case typeName == "":
+			continue
+		case strings.EqualFold(typeName, "ALL"):
+			for _, name := range names {
+				selected[name] = true
+			}
+			continue
+		case typeName == "secrets-variables":
+			selected["secrets"] = true
+			selected["variables"] = true
+			continue
+		}
+		found := false
+		for _, resource := range Resources {
+			if typeName == resource.Name || slices.Contains(resource.Aliases, typeName) {
+				selected[resource.Name] = true
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown type %q. Options: ALL or a comma-separated list of %s", typeName, strings.Join(names, ", "))
+		}
+	}
+
+	var resources []string
+	for _, resource := range Resources {
+		if selected[resource.Name] && (resource.Named || len(filter.Names) == 0) {
+			resources = append(resources, resource.Name)
+		}
+	}
+	if len(resources) == 0 && len(filter.Names) > 0 {
+		return nil, fmt.Errorf("--name only selects secrets and variables, use --type secrets,variables")
+	}
+	return resources, nil
+}
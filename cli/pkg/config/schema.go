@@ -0,0 +1,77 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Schema returns a JSON Schema for the config file, generated from the yaml tags of types.Config
+// so that it always matches what Load accepts. Editors use it for completion and validation.
+func Schema() ([]byte, error) {
+	schema := jsonSchema(reflect.TypeOf(types.Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "AutoGit configuration"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == valuesType:
+		// Scalars are used as written, maps and lists are stored as JSON and null removes an inherited value
+		return map[string]interface{}{
+			"type":          "object",
+			"propertyNames": map[string]interface{}{"pattern": namePattern.String()},
+			"additionalProperties": map[string]interface{}{
+				"type": []string{"string", "number", "boolean", "object", "array", "null"},
+			},
+		}
+	case t == stringListType:
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" || !field.IsExported() {
+				continue
+			}
+			properties[name] = jsonSchema(field.Type)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": jsonSchema(t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": jsonSchema(t.Elem()),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
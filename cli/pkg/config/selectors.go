@@ -0,0 +1,88 @@
+package config
+
+import (
+	"path"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// MatchRepo reports whether a repository matches every criterion of a selector.
+// teamRepos holds the names of the repositories of match.Team.
+func MatchRepo(match types.RepoMatch, repo types.Repository, teamRepos map[string]bool) bool {
+	if match.ExcludeArchived && repo.Archived {
+		return false
+	}
+
+	if len(match.Names) > 0 {
+		matched := false
+		for _, pattern := range match.Names {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(repo.Name)); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, topic := range match.Topics {
+		found := false
+		for _, repoTopic := range repo.Topics {
+			if strings.EqualFold(topic, repoTopic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if match.Team != "" && !teamRepos[strings.ToLower(repo.Name)] {
+		return false
+	}
+
+	for property, value := range match.CustomProperties {
+		if repo.CustomProperties[property] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandRepoSelectors adds the environments of every selector of an organization to each repository it
+// matches. Environments configured explicitly under repos win over selectors, and earlier selectors win
+// over later ones. teamRepos maps team slugs to the lower-cased names of their repositories.
+func ExpandRepoSelectors(org *types.OrgConfig, repos []types.Repository, teamRepos map[string]map[string]bool) {
+	if org.Repos == nil {
+		org.Repos = make(map[string]map[string]types.DeploymentEnvOptions)
+	}
+
+	// Repository names are case-insensitive, so explicit entries are found regardless of case
+	existing := make(map[string]string, len(org.Repos))
+	for repoName := range org.Repos {
+		existing[strings.ToLower(repoName)] = repoName
+	}
+
+	for _, selector := range org.RepoSelectors {
+		for _, repo := range repos {
+			if !MatchRepo(selector.Match, repo, teamRepos[selector.Match.Team]) {
+				continue
+			}
+			repoName, ok := existing[strings.ToLower(repo.Name)]
+			if !ok {
+				repoName = repo.Name
+				existing[strings.ToLower(repoName)] = repoName
+				org.Repos[repoName] = make(map[string]types.DeploymentEnvOptions)
+			}
+			for envName, envOptions := range selector.Environments {
+				if _, configured := org.Repos[repoName][envName]; !configured {
+					org.Repos[repoName][envName] = envOptions
+				}
+			}
+		}
+	}
+	org.RepoSelectors = nil
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/freeze"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in the config file, with the position it was found at
+type ValidationError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// ValidationErrors collects every problem found in a config file
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e *ValidationErrors) add(files sourceFiles, node *yaml.Node, format string, args ...interface{}) {
+	*e = append(*e, ValidationError{File: files.of(node), Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)})
+}
+
+// Secret and variable names may only contain alphanumerics and underscores and may not start with a number
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var (
+	valuesType     = reflect.TypeOf(types.Values{})
+	stringListType = reflect.TypeOf(types.StringList{})
+)
+
+// yamlFields maps the yaml key of every field of a struct type to the field type
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// checkKeys reports keys in node that do not exist in the yaml tags of t, walking nested structs, maps and lists
+func checkKeys(files sourceFiles, node *yaml.Node, t reflect.Type, errs *ValidationErrors) {
+	node = resolveAlias(node)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node == nil || (node.Kind == yaml.ScalarNode && node.Tag == "!!null") || t == valuesType || t == stringListType {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			errs.add(files, node, "expected a map")
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				errs.add(files, key, "unknown key %q%s", key.Value, suggestKey(key.Value, fields))
+				continue
+			}
+			checkKeys(files, node.Content[i+1], fieldType, errs)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			errs.add(files, node, "expected a map")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkKeys(files, node.Content[i+1], t.Elem(), errs)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			errs.add(files, node, "expected a list")
+			return
+		}
+		for _, item := range node.Content {
+			checkKeys(files, item, t.Elem(), errs)
+		}
+	}
+}
+
+// suggestKey points out known keys that only differ by case, the most common typo, or by a plural s
+func suggestKey(key string, fields map[string]reflect.Type) string {
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return fmt.Sprintf(" (did you mean %q? keys are case-sensitive)", name)
+		}
+	}
+	for name := range fields {
+		if strings.EqualFold(name, key+"s") || strings.EqualFold(name+"s", key) {
+			return fmt.Sprintf(" (did you mean %q?)", name)
+		}
+	}
+	return ""
+}
+
+// validateResolved checks the names and values of the resolved repos, environments, variables and secrets
+func validateResolved(files sourceFiles, root *yaml.Node, errs *ValidationErrors) {
+	validateScope(files, "", root, errs)
+	validateFreezes(files, mappingValue(root, "freezes"), errs)
+	if orgsNode := mappingValue(root, "orgs"); orgsNode != nil && orgsNode.Kind == yaml.MappingNode {
+		checkDuplicates(files, orgsNode, "organization", errs)
+		for i := 0; i+1 < len(orgsNode.Content); i += 2 {
+			validateScope(files, orgsNode.Content[i].Value+"/", resolveAlias(orgsNode.Content[i+1]), errs)
+		}
+	}
+}
+
+// validateFreezes checks every freeze has a valid schedule or range and timezone
+func validateFreezes(files sourceFiles, freezes *yaml.Node, errs *ValidationErrors) {
+	if freezes == nil || freezes.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, node := range freezes.Content {
+		var f types.Freeze
+		if err := node.Decode(&f); err != nil {
+			errs.add(files, node, "invalid freeze: %v", err)
+			continue
+		}
+		if f.Name == "" {
+			errs.add(files, node, "freeze needs a name")
+		}
+		if err := freeze.Validate(f); err != nil {
+			errs.add(files, node, "freeze %q: %v", f.Name, err)
+		}
+	}
+}
+
+// validateScope checks the repos and repoSelectors of the top level or of one organization
+func validateScope(files sourceFiles, prefix string, scope *yaml.Node, errs *ValidationErrors) {
+	if repos := mappingValue(scope, "repos"); repos != nil && repos.Kind == yaml.MappingNode {
+		checkDuplicates(files, repos, "repository", errs)
+		for i := 0; i+1 < len(repos.Content); i += 2 {
+			validateEnvironments(files, prefix+repos.Content[i].Value, resolveAlias(repos.Content[i+1]), errs)
+		}
+	}
+	if selectors := mappingValue(scope, "repoSelectors"); selectors != nil && selectors.Kind == yaml.SequenceNode {
+		for i, selector := range selectors.Content {
+			if match := mappingValue(selector, "match"); match == nil || len(match.Content) == 0 {
+				errs.add(files, selector, "%srepoSelectors[%d] needs at least one match criterion", prefix, i)
+			} else if names := mappingValue(match, "names"); names != nil {
+				for _, pattern := range names.Content {
+					if _, err := path.Match(pattern.Value, ""); err != nil {
+						errs.add(files, pattern, "invalid name pattern %q: %v", pattern.Value, err)
+					}
+				}
+			}
+			validateEnvironments(files, fmt.Sprintf("%srepoSelectors[%d]", prefix, i), mappingValue(selector, "environments"), errs)
+		}
+	}
+}
+
+// validateEnvironments checks the environments of one repository or selector
+func validateEnvironments(files sourceFiles, repoName string, environments *yaml.Node, errs *ValidationErrors) {
+	if environments == nil || environments.Kind != yaml.MappingNode {
+		return
+	}
+
+	checkDuplicates(files, environments, fmt.Sprintf("environment in %s", repoName), errs)
+	for j := 0; j+1 < len(environments.Content); j += 2 {
+		envName, envNode := environments.Content[j], resolveAlias(environments.Content[j+1])
+		switch {
+		case strings.TrimSpace(envName.Value) == "":
+			errs.add(files, envName, "environment name in %s may not be empty", repoName)
+		case len(envName.Value) > 255:
+			errs.add(files, envName, "environment name %q is longer than 255 characters", envName.Value)
+		case strings.TrimSpace(envName.Value) != envName.Value:
+			errs.add(files, envName, "environment name %q has leading or trailing spaces", envName.Value)
+		case strings.ContainsAny(envName.Value, "/\\"):
+			errs.add(files, envName, "environment name %q may not contain slashes", envName.Value)
+		}
+
+		createSecrets := mappingValue(envNode, "createSecrets")
+
+		// References are checked against a scope with the names known at this point; repositories
+		// matched by selectors only get their real name once the selectors are expanded.
+		var vars types.Values
+		if variables := mappingValue(envNode, "variables"); variables != nil {
+			_ = variables.Decode(&vars)
+		}
+		scope := NewScope("org", repoName, envName.Value, vars)
+		for _, kind := range []string{"variables", "secrets"} {
+			values := mappingValue(envNode, kind)
+			if values == nil || values.Kind != yaml.MappingNode {
+				continue
+			}
+			checkDuplicates(files, values, fmt.Sprintf("%s name in %s/%s", strings.TrimSuffix(kind, "s"), repoName, envName.Value), errs)
+			for k := 0; k+1 < len(values.Content); k += 2 {
+				name, value := values.Content[k], resolveAlias(values.Content[k+1])
+				checkName(files, name, strings.TrimSuffix(kind, "s"), errs)
+				if value.Kind == yaml.ScalarNode {
+					if _, err := scope.Interpolate(value.Value); err != nil {
+						errs.add(files, value, "%s %s in %s/%s: %v", strings.TrimSuffix(kind, "s"), name.Value, repoName, envName.Value, err)
+					}
+				}
+				if value.Kind == yaml.ScalarNode && value.Value == "" {
+					errs.add(files, value, "%s %s in %s/%s has an empty value", strings.TrimSuffix(kind, "s"), name.Value, repoName, envName.Value)
+				}
+				if kind == "secrets" && value.Value == types.SecretPlaceholder && createSecrets != nil && createSecrets.Value == "true" {
+					errs.add(files, value, "secret %s in %s/%s still has the %s placeholder value", name.Value, repoName, envName.Value, types.SecretPlaceholder)
+				}
+			}
+		}
+	}
+}
+
+// checkName applies GitHub's naming rules for secrets and variables
+func checkName(files sourceFiles, name *yaml.Node, kind string, errs *ValidationErrors) {
+	switch {
+	case !namePattern.MatchString(name.Value):
+		errs.add(files, name, "%s name %q may only contain letters, numbers and underscores and may not start with a number", kind, name.Value)
+	case strings.HasPrefix(strings.ToUpper(name.Value), "GITHUB_"):
+		errs.add(files, name, "%s name %q may not start with the reserved GITHUB_ prefix", kind, name.Value)
+	}
+}
+
+// checkDuplicates reports keys of a mapping node that only differ by case, as GitHub treats them as the same name
+func checkDuplicates(files sourceFiles, node *yaml.Node, what string, errs *ValidationErrors) {
+	seen := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if first, ok := seen[strings.ToLower(key.Value)]; ok {
+			errs.add(files, key, "%s %q only differs by case from %q at %s:%d", what, key.Value, first.Value, files.of(first), first.Line)
+			continue
+		}
+		seen[strings.ToLower(key.Value)] = key
+	}
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetEnvironmentValues writes variables and secrets into an environment of the config, keeping comments
+// and layout, and returns the file written. The environment is updated in the file that defines it, which
+// can be one pulled in with `include:`, and is added to the file at path otherwise. Directories and glob
+// patterns are refused as it is not clear which file a new environment belongs in.
+// Missing orgs, repos and environments are added, existing values are replaced, and
+// createVariables/createSecrets are switched on when the environment does not set them.
+func SetEnvironmentValues(path, org, repo, env string, variables, secrets map[string]string) (string, error) {
+	if strings.ContainsAny(path, "*?[") {
+		return "", fmt.Errorf("cannot save to the config pattern %s, give the config file to write with --config", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("cannot save to the config directory %s, give the config file to write with --config", path)
+	}
+
+	// Find the file defining the environment. A config that does not load yet is edited as a single file.
+	target, legacy := path, false
+	if merged, files, err := loadDocuments(path); err == nil {
+		if envNode := findEnvironment(merged, org, repo, env); envNode != nil {
+			target = files.of(envNode)
+		}
+		topOrg := mappingValue(merged, "org")
+		legacy = topOrg != nil && topOrg.Value == org
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return "", err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("%s: %w", target, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("%s: the config must be a mapping", target)
+	}
+	if topOrg := mappingValue(root, "org"); topOrg != nil && topOrg.Value == org {
+		legacy = true
+	}
+
+	// Legacy single-org configs keep using top-level repos, everything else goes under orgs
+	scope := root
+	if orgNode := mappingValue(mappingValue(root, "orgs"), org); !legacy || findEnvironment(orgNode, "", repo, env) != nil {
+		scope = ensureMapping(ensureMapping(root, "orgs"), org)
+	}
+	envNode := ensureMapping(ensureMapping(ensureMapping(scope, "repos"), repo), env)
+
+	if len(variables) > 0 {
+		setDefault(envNode, "createVariables", "true", "!!bool")
+		setValues(ensureMapping(envNode, "variables"), variables)
+	}
+	if len(secrets) > 0 {
+		setDefault(envNode, "createSecrets", "true", "!!bool")
+		setValues(ensureMapping(envNode, "secrets"), secrets)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", target, err)
+	}
+	return target, os.WriteFile(target, out.Bytes(), 0644)
+}
+
+// findEnvironment returns the node of an environment under orgs, or under the top-level repos when
+// the config's org is the one asked for. With an empty org only the scope's own repos are looked at.
+func findEnvironment(scope *yaml.Node, org, repo, env string) *yaml.Node {
+	if org == "" {
+		return mappingValue(mappingValue(mappingValue(scope, "repos"), repo), env)
+	}
+	if envNode := findEnvironment(mappingValue(mappingValue(scope, "orgs"), org), "", repo, env); envNode != nil {
+		return envNode
+	}
+	if topOrg := mappingValue(scope, "org"); topOrg != nil && topOrg.Value == org {
+		return findEnvironment(scope, "", repo, env)
+	}
+	return nil
+}
+
+// ensureMapping returns the mapping under key, adding it (or replacing a null) when needed
+func ensureMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	removeKey(node, key)
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+func setDefault(node *yaml.Node, key, value, tag string) {
+	if mappingValue(node, key) == nil {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value})
+	}
+}
+
+func setValues(node *yaml.Node, values map[string]string) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: values[name]}
+		if existing := mappingValue(node, name); existing != nil {
+			*existing = *value
+			continue
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+	}
+}
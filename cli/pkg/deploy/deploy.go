@@ -0,0 +1,137 @@
+// Package deploy picks deployments and workflow runs for deploy, compare, promote, rollback and review
+// from what GitHub reports.
+package deploy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// StatusFetcher returns the statuses of a deployment, newest first
+type StatusFetcher func(deploymentID int) ([]types.DeploymentStatus, error)
+
+// Succeeded reports whether a deployment succeeded, including ones made inactive by a newer deployment
+func Succeeded(statuses []types.DeploymentStatus) bool {
+	if len(statuses) == 0 || (statuses[0].State != "success" && statuses[0].State != "inactive") {
+		return false
+	}
+	for _, status := range statuses {
+		if status.State == "success" {
+			return true
+		}
+	}
+	return false
+}
+
+// LastSuccessful returns the newest deployment that succeeded, skipping failed and unfinished ones.
+// Deployments are newest first, as GitHub returns them.
+func LastSuccessful(deployments []types.Deployment, statuses StatusFetcher) (types.Deployment, error) {
+	for _, deployment := range deployments {
+		deploymentStatuses, err := statuses(deployment.ID)
+		if err != nil {
+			return types.Deployment{}, err
+		}
+		if Succeeded(deploymentStatuses) {
+			return deployment, nil
+		}
+	}
+	return types.Deployment{}, fmt.Errorf("no successful deployment")
+}
+
+// RollbackTarget returns the current deployment and the last successful deployment of another commit before it.
+// Deployments are newest first, as GitHub returns them.
+func RollbackTarget(deployments []types.Deployment, statuses StatusFetcher) (types.Deployment, types.Deployment, error) {
+	if len(deployments) == 0 {
+		return types.Deployment{}, types.Deployment{}, fmt.Errorf("the environment has no deployments")
+	}
+	current := deployments[0]
+	for _, deployment := range deployments[1:] {
+		if deployment.SHA == current.SHA {
+			continue
+		}
+		deploymentStatuses, err := statuses(deployment.ID)
+		if err != nil {
+			return current, types.Deployment{}, err
+		}
+		if Succeeded(deploymentStatuses) {
+			return current, deployment, nil
+		}
+	}
+	return current, types.Deployment{}, fmt.Errorf("no successful deployment of another commit before %s", current.Ref)
+}
+
+// PromotionSource returns the environment a promotion to an environment deploys from: from, the --from flag,
+// or the target's promoteFrom in the config. Both must agree when both are set.
+func PromotionSource(from, to string, target types.DeploymentEnvOptions) (string, error) {
+	switch {
+	case from == "":
+		from = target.PromoteFrom
+	case target.PromoteFrom != "" && target.PromoteFrom != from:
+		return "", fmt.Errorf("%s is promoted from %s in the config", to, target.PromoteFrom)
+	}
+	if from == "" {
+		return "", fmt.Errorf("no --from given and %s has no promoteFrom in the config", to)
+	}
+	return from, nil
+}
+
+// RefMoved reports whether the ref of a deployment now points at another commit than the one deployed.
+// Dispatches run on a branch or tag, so redeploying a moved ref would deploy something else.
+func RefMoved(deployedSHA, currentSHA string) bool {
+	return deployedSHA != "" && currentSHA != deployedSHA
+}
+
+// DispatchedRun picks the run a dispatch started among runs not seen before it. With a correlation ID
+// the run-name must contain it, otherwise the run must be on ref and, when known, by actor.
+func DispatchedRun(runs []api.WorkflowRun, seen map[int]bool, ref, actor, correlationID string) *api.WorkflowRun {
+	branch := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+	for _, run := range runs {
+		switch {
+		case seen[run.ID]:
+		case correlationID != "":
+			if strings.Contains(run.DisplayTitle, correlationID) {
+				return &run
+			}
+		case run.HeadBranch == branch && (actor == "" || strings.EqualFold(run.Actor.Login, actor)):
+			return &run
+		}
+	}
+	return nil
+}
+
+// Reviewable returns the pending deployments of a run the current user can review and the filter selects
+func Reviewable(repo string, pending []api.PendingDeployment, filter config.Filter) []api.PendingDeployment {
+	var reviewable []api.PendingDeployment
+	for _, deployment := range pending {
+		if deployment.CurrentUserCanApprove && filter.MatchEnv(repo, deployment.Environment.Name) {
+			reviewable = append(reviewable, deployment)
+		}
+	}
+	return reviewable
+}
+
+// ParseSelection reads a picker answer such as "1,3", "2 4" or "all" into indexes of count items.
+// Choices that are not item numbers are returned as invalid.
+func ParseSelection(answer string, count int) (selected []int, invalid []string) {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer == "all" {
+		for i := 0; i < count; i++ {
+			selected = append(selected, i)
+		}
+		return selected, nil
+	}
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ' ' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > count {
+			invalid = append(invalid, field)
+			continue
+		}
+		selected = append(selected, n-1)
+	}
+	return selected, invalid
+}
@@ -0,0 +1,182 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Statuses reported for each compared item
+const (
+	Unchanged = "Unchanged"
+	Drifted   = "Drifted"
+	Missing   = "Missing"
+	Unmanaged = "Unmanaged"
+)
+
+// Result is the outcome of comparing one configured item with what exists in GitHub
+type Result struct {
+	Org      string
+	Repo     string
+	Env      string
+	Kind     string // environment, variable or secret
+	Name     string
+	Expected string
+	Actual   string
+	Status   string
+}
+
+// CompareValue compares a configured value with the one in GitHub using types.SameValue,
+// the same check CreateUpdateVariable does when a variable already exists.
+func CompareValue(expected, actual string) string {
+	if types.SameValue(expected, actual) {
+		return Unchanged
+	}
+	return Drifted
+}
+
+// CompareVariables compares configured variables with those in an environment.
+// GitHub upper-cases variable names, so names are compared case-insensitively.
+func CompareVariables(repo, env string, expected types.Values, actual []types.Variable) []Result {
+	actualByName := make(map[string]types.Variable, len(actual))
+	for _, variable := range actual {
+		actualByName[strings.ToUpper(variable.Name)] = variable
+	}
+
+	var results []Result
+	for name, value := range expected {
+		result := Result{Repo: repo, Env: env, Kind: "variable", Name: name, Expected: value}
+		if variable, ok := actualByName[strings.ToUpper(name)]; ok {
+			result.Actual = variable.Value
+			result.Status = CompareValue(value, variable.Value)
+			delete(actualByName, strings.ToUpper(name))
+		} else {
+			result.Status = Missing
+		}
+		results = append(results, result)
+	}
+	for _, variable := range actualByName {
+		results = append(results, Result{Repo: repo, Env: env, Kind: "variable", Name: variable.Name, Actual: variable.Value, Status: Unmanaged})
+	}
+	return results
+}
+
+// CompareSecrets compares configured secret names with those in an environment.
+// Secret values are never returned by GitHub so only their presence can drift.
+func CompareSecrets(repo, env string, expected types.Values, actual []types.SecretMetadata) []Result {
+	actualByName := make(map[string]types.SecretMetadata, len(actual))
+	for _, secret := range actual {
+		actualByName[strings.ToUpper(secret.Name)] = secret
+	}
+
+	var results []Result
+	for name := range expected {
+		result := Result{Repo: repo, Env: env, Kind: "secret", Name: name, Expected: "***"}
+		if _, ok := actualByName[strings.ToUpper(name)]; ok {
+			result.Actual = "***"
+			result.Status = Unchanged
+			delete(actualByName, strings.ToUpper(name))
+		} else {
+			result.Status = Missing
+		}
+		results = append(results, result)
+	}
+	for _, secret := range actualByName {
+		results = append(results, Result{Repo: repo, Env: env, Kind: "secret", Name: secret.Name, Actual: "***", Status: Unmanaged})
+	}
+	return results
+}
+
+// CompareEnvironment compares the configured protection rules of an environment with the actual ones.
+// Like CreateDeploymentEnv, only settings present in the config are considered managed.
+// exists is false when the environment is not in GitHub at all.
+func CompareEnvironment(repo, env string, expected, actual types.DeploymentEnvOptions, exists bool) []Result {
+	if !exists {
+		return []Result{{Repo: repo, Env: env, Kind: "environment", Name: "N/A", Expected: "exists", Status: Missing}}
+	}
+
+	var results []Result
+	compare := func(name, expectedValue, actualValue string) {
+		results = append(results, Result{
+			Repo:     repo,
+			Env:      env,
+			Kind:     "environment",
+			Name:     name,
+			Expected: expectedValue,
+			Actual:   actualValue,
+			Status:   CompareValue(expectedValue, actualValue),
+		})
+	}
+
+	if expected.WaitTimer != nil {
+		compare("waitTimer", fmt.Sprint(*expected.WaitTimer), fmt.Sprint(valueOf(actual.WaitTimer)))
+	}
+	if expected.Reviewers != nil {
+		compare("reviewers", reviewerList(expected.Reviewers), reviewerList(actual.Reviewers))
+	}
+	if expected.PreventSelfReview != nil {
+		compare("preventSelfReview", fmt.Sprint(*expected.PreventSelfReview), fmt.Sprint(valueOf(actual.PreventSelfReview)))
+	}
+	if expected.DeploymentBranchPolicy != nil {
+		compare("deploymentBranchPolicy", branchPolicyString(expected.DeploymentBranchPolicy), branchPolicyString(actual.DeploymentBranchPolicy))
+	}
+	if len(expected.BranchPolicies) > 0 {
+		compare("branchPolicies", branchPolicyList(expected.BranchPolicies), branchPolicyList(actual.BranchPolicies))
+	}
+	if len(results) == 0 {
+		results = append(results, Result{Repo: repo, Env: env, Kind: "environment", Name: "N/A", Expected: "exists", Actual: "exists", Status: Unchanged})
+	}
+	return results
+}
+
+// HasDrift reports whether any result is not Unchanged, optionally ignoring unmanaged items.
+func HasDrift(results []Result, ignoreUnmanaged bool) bool {
+	for _, result := range results {
+		if result.Status == Unchanged || (ignoreUnmanaged && result.Status == Unmanaged) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// reviewerList renders reviewers as a sorted list so ordering differences are not reported as drift
+func reviewerList(reviewers []types.Reviewer) string {
+	var entries []string
+	for _, reviewer := range reviewers {
+		entries = append(entries, fmt.Sprintf("%s:%d", reviewer.Type, reviewer.ID))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func branchPolicyString(policy *types.DeploymentBranchPolicy) string {
+	if policy == nil {
+		return "all branches"
+	}
+	return fmt.Sprintf("protectedBranches=%t,customBranchPolicies=%t", policy.ProtectedBranches, policy.CustomBranchPolicies)
+}
+
+func branchPolicyList(policies []types.BranchPolicy) string {
+	var entries []string
+	for _, policy := range policies {
+		policyType := policy.Type
+		if policyType == "" {
+			policyType = "branch"
+		}
+		entries = append(entries, policyType+":"+policy.Name)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// valueOf returns what a pointer points to, or the zero value for nil, which is how GitHub reports unset rules
+func valueOf[T any](p *T) T {
+	var value T
+	if p != nil {
+		value = *p
+	}
+	return value
+}
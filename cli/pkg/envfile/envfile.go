@@ -0,0 +1,226 @@
+// Package envfile reads variables and secrets handed over as dotenv or flat JSON files.
+package envfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// Load reads a .json file, or any file starting with '{', as flat JSON and anything else as dotenv
+func Load(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	if strings.EqualFold(filepath.Ext(path), ".json") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		values, err = ParseJSON(data)
+	} else {
+		values, err = ParseDotenv(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// ParseJSON reads a flat JSON object. Numbers and booleans are kept as written,
+// nested objects and arrays are stored as their JSON encoding like structured config values.
+func ParseJSON(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON object: %w", err)
+	}
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		var s string
+		switch {
+		case json.Unmarshal(value, &s) == nil:
+			values[key] = s
+		case string(value) == "null":
+			values[key] = ""
+		default:
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, value); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			values[key] = compact.String()
+		}
+	}
+	return values, nil
+}
+
+// ParseDotenv reads KEY=value lines. It supports:
+//   - blank lines, # comments and an optional `export ` prefix
+//   - unquoted values, trimmed and ending at ` #`
+//   - 'single quoted' values taken literally, which may span lines
+//   - "double quoted" values with \n, \t, \" and \\ escapes, which may span lines
+func ParseDotenv(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+	input := strings.ReplaceAll(string(data), "\r\n", "\n")
+	line := 1
+	for len(input) > 0 {
+		var current string
+		current, input = cut(input)
+		startLine := line
+		line++
+
+		trimmed := strings.TrimSpace(current)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		trimmed = strings.TrimPrefix(trimmed, "export ")
+		key, rest, ok := strings.Cut(trimmed, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", startLine)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+			if i := strings.Index(rest, " #"); i >= 0 {
+				rest = rest[:i]
+			}
+			values[key] = strings.TrimSpace(rest)
+			continue
+		}
+
+		// Quoted values run until the closing quote, reading further lines if needed
+		quote := rest[0]
+		value := rest[1:]
+		for {
+			if end := closingQuote(value, quote); end >= 0 {
+				if tail := strings.TrimSpace(value[end+1:]); tail != "" && !strings.HasPrefix(tail, "#") {
+					return nil, fmt.Errorf("line %d: unexpected %q after quoted value", line-1, tail)
+				}
+				value = value[:end]
+				break
+			}
+			if input == "" {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for %s", startLine, key)
+			}
+			var next string
+			next, input = cut(input)
+			line++
+			value += "\n" + next
+		}
+		if quote == '"' {
+			value = unescape(value)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// cut splits off the first line
+func cut(input string) (string, string) {
+	if i := strings.IndexByte(input, '\n'); i >= 0 {
+		return input[:i], input[i+1:]
+	}
+	return input, ""
+}
+
+// closingQuote finds the quote ending a value, skipping escaped double quotes
+func closingQuote(value string, quote byte) int {
+	for i := 0; i < len(value); i++ {
+		if quote == '"' && value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+func unescape(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\', '$':
+			b.WriteByte(value[i])
+		default:
+			// Unknown escapes are kept as written
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// Merge layers values on top of each other, later layers winning like environment variables override
+// repository variables, which override organization variables. Names are compared case-insensitively
+// and the name of the winning layer is kept.
+func Merge(layers ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, layer := range layers {
+		for name, value := range layer {
+			for existing := range merged {
+				if strings.EqualFold(existing, name) {
+					delete(merged, existing)
+				}
+			}
+			merged[name] = value
+		}
+	}
+	return merged
+}
+
+// Format renders values as dotenv, json or yaml, sorted by name
+func Format(values map[string]string, format string) ([]byte, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch format {
+	case "dotenv", "env", "":
+		var b strings.Builder
+		for _, name := range names {
+			fmt.Fprintf(&b, "%s=%s\n", name, quote(values[name]))
+		}
+		return []byte(b.String()), nil
+	case "json":
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case "yaml":
+		return yaml.Marshal(values)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected dotenv, json or yaml", format)
+	}
+}
+
+// quote leaves simple values bare and double quotes anything ParseDotenv would otherwise change
+func quote(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t\n\r\"'#\\$") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`, "$", `\$`)
+	return `"` + replacer.Replace(value) + `"`
+}
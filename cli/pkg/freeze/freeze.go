@@ -0,0 +1,167 @@
+// Package freeze works out when change freezes declared in the config are in effect.
+package freeze
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // timezones work on machines without a zoneinfo database
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Window is one period during which a freeze is in effect
+type Window struct {
+	Freeze types.Freeze
+	Start  time.Time
+	End    time.Time
+}
+
+// Layouts accepted for absolute start and end times, read in the freeze's timezone
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"}
+
+// Validate checks a freeze is either a schedule with a duration or an absolute range, with a known timezone
+func Validate(freeze types.Freeze) error {
+	_, err := compile(freeze)
+	return err
+}
+
+// compiled is a freeze with its times parsed
+type compiled struct {
+	freeze     types.Freeze
+	location   *time.Location
+	schedule   *schedule
+	duration   time.Duration
+	start, end time.Time
+}
+
+func compile(freeze types.Freeze) (*compiled, error) {
+	c := &compiled{freeze: freeze, location: time.Local}
+	if freeze.Timezone != "" {
+		location, err := time.LoadLocation(freeze.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", freeze.Timezone)
+		}
+		c.location = location
+	}
+
+	recurring := freeze.Schedule != "" || freeze.Duration != ""
+	absolute := freeze.Start != "" || freeze.End != ""
+	switch {
+	case recurring && absolute:
+		return nil, fmt.Errorf("use either schedule and duration or start and end")
+	case recurring:
+		if freeze.Schedule == "" || freeze.Duration == "" {
+			return nil, fmt.Errorf("a recurring freeze needs both schedule and duration")
+		}
+		var err error
+		if c.schedule, err = parseSchedule(freeze.Schedule); err != nil {
+			return nil, err
+		}
+		if c.duration, err = time.ParseDuration(freeze.Duration); err != nil || c.duration <= 0 {
+			return nil, fmt.Errorf("invalid duration %q, use e.g. 90m or 57h", freeze.Duration)
+		}
+	case absolute:
+		var err error
+		if c.start, err = parseTime(freeze.Start, c.location); err != nil {
+			return nil, fmt.Errorf("invalid start: %w", err)
+		}
+		if c.end, err = parseTime(freeze.End, c.location); err != nil {
+			return nil, fmt.Errorf("invalid end: %w", err)
+		}
+		if !c.end.After(c.start) {
+			return nil, fmt.Errorf("end must be after start")
+		}
+	default:
+		return nil, fmt.Errorf("a freeze needs schedule and duration, or start and end")
+	}
+	for _, pattern := range append(append(append([]string{}, freeze.Orgs...), freeze.Repos...), freeze.Environments...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	return c, nil
+}
+
+func parseTime(value string, location *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing")
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date like 2006-01-02 or 2006-01-02T15:04", value)
+}
+
+// Applies reports whether a freeze covers an environment. Empty lists cover everything.
+func Applies(freeze types.Freeze, org, repo, env string) bool {
+	return matchAny(freeze.Orgs, org) && matchAny(freeze.Repos, repo) && matchAny(freeze.Environments, env)
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(value)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Active returns the windows in effect at now for an environment
+func Active(freezes []types.Freeze, org, repo, env string, now time.Time) []Window {
+	var active []Window
+	for _, window := range Windows(freezes, now, now) {
+		if Applies(window.Freeze, org, repo, env) {
+			active = append(active, window)
+		}
+	}
+	return active
+}
+
+// Windows returns the windows of every freeze overlapping from..until, ordered by start.
+// Invalid freezes are skipped, config.Load reports them.
+func Windows(freezes []types.Freeze, from, until time.Time) []Window {
+	var windows []Window
+	for _, freeze := range freezes {
+		c, err := compile(freeze)
+		if err != nil {
+			continue
+		}
+		windows = append(windows, c.windows(from, until)...)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Start.Before(windows[j].Start) })
+	return windows
+}
+
+func (c *compiled) windows(from, until time.Time) []Window {
+	if c.schedule == nil {
+		if c.start.After(until) || !c.end.After(from) {
+			return nil
+		}
+		return []Window{{Freeze: c.freeze, Start: c.start, End: c.end}}
+	}
+
+	// Every minute a window could have started in is checked, in the freeze's timezone, with an hour
+	// of slack as wall clock durations can be longer over a daylight saving change
+	var windows []Window
+	t := from.Add(-c.duration - time.Hour).In(c.location).Truncate(time.Minute)
+	for ; !t.After(until); t = t.Add(time.Minute) {
+		if end := wallClockAdd(t, c.duration); c.schedule.matches(t) && end.After(from) {
+			windows = append(windows, Window{Freeze: c.freeze, Start: t, End: end})
+		}
+	}
+	return windows
+}
+
+// wallClockAdd adds a duration on the clock of t's timezone, so that Friday 15:00 plus 57h is
+// Monday 00:00 even when daylight saving time changes over the weekend
+func wallClockAdd(t time.Time, d time.Duration) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+int(d/time.Minute), 0, 0, t.Location())
+}
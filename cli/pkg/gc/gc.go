@@ -0,0 +1,110 @@
+// Package gc picks the deployments and environments that are no longer needed.
+package gc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Policy says what is kept. Environments are matched case-insensitively like GitHub does.
+type Policy struct {
+	// Configured environments of the repository, their newest deployment is always kept
+	Configured map[string]bool
+	// Retention removes deployments of configured environments older than this, zero keeps them all
+	Retention time.Duration
+	// IdleEnvironments removes environments missing from the config without deployments for this long, zero keeps them
+	IdleEnvironments time.Duration
+	// Ignored environments are left alone entirely, e.g. preview environments that preview gc cleans up
+	Ignored func(env string) bool
+	Now     time.Time
+}
+
+// DeploymentCandidate is a deployment to remove and why
+type DeploymentCandidate struct {
+	Deployment types.Deployment
+	Reason     string
+}
+
+// EnvironmentCandidate is an environment to remove and why
+type EnvironmentCandidate struct {
+	Environment types.Environment
+	Reason      string
+}
+
+func (p Policy) configured(env string) bool {
+	for name := range p.Configured {
+		if strings.EqualFold(name, env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Policy) ignored(env string) bool {
+	return p.Ignored != nil && p.Ignored(env)
+}
+
+// Deployments returns the deployments to remove: every deployment of an environment missing from the config,
+// and deployments of configured environments older than the retention, except the newest one of each.
+func (p Policy) Deployments(deployments []types.Deployment) []DeploymentCandidate {
+	newest := make(map[string]types.Deployment)
+	for _, deployment := range deployments {
+		env := strings.ToLower(deployment.Environment)
+		if current, ok := newest[env]; !ok || deployment.CreatedAt.After(current.CreatedAt) {
+			newest[env] = deployment
+		}
+	}
+
+	var candidates []DeploymentCandidate
+	for _, deployment := range deployments {
+		switch {
+		case p.ignored(deployment.Environment):
+		case !p.configured(deployment.Environment):
+			candidates = append(candidates, DeploymentCandidate{Deployment: deployment, Reason: "environment not in config"})
+		case p.Retention > 0 && newest[strings.ToLower(deployment.Environment)].ID != deployment.ID && p.Now.Sub(deployment.CreatedAt) > p.Retention:
+			candidates = append(candidates, DeploymentCandidate{Deployment: deployment, Reason: fmt.Sprintf("older than %s", formatDays(p.Retention))})
+		}
+	}
+	return candidates
+}
+
+// Environments returns the environments missing from the config whose last deployment, or creation when they
+// have none, is older than IdleEnvironments
+func (p Policy) Environments(environments []types.Environment, deployments []types.Deployment) []EnvironmentCandidate {
+	if p.IdleEnvironments <= 0 {
+		return nil
+	}
+	lastUsed := make(map[string]time.Time)
+	for _, deployment := range deployments {
+		env := strings.ToLower(deployment.Environment)
+		if deployment.CreatedAt.After(lastUsed[env]) {
+			lastUsed[env] = deployment.CreatedAt
+		}
+	}
+
+	var candidates []EnvironmentCandidate
+	for _, environment := range environments {
+		if p.configured(environment.Name) || p.ignored(environment.Name) {
+			continue
+		}
+		last, ok := lastUsed[strings.ToLower(environment.Name)]
+		if !ok {
+			created, err := time.Parse(time.RFC3339, environment.CreatedAt)
+			if err != nil {
+				continue
+			}
+			last = created
+		}
+		if p.Now.Sub(last) > p.IdleEnvironments {
+			candidates = append(candidates, EnvironmentCandidate{Environment: environment, Reason: fmt.Sprintf("no deployments for %s", formatDays(p.IdleEnvironments))})
+		}
+	}
+	return candidates
+}
+
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
@@ -0,0 +1,67 @@
+// Package preview builds the per-pull-request preview environments from the `previews:` config.
+package preview
+
+import (
+	"maps"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// DefaultPrefix is put in front of the pull request number when previews set no prefix
+const DefaultPrefix = "pr-"
+
+func prefix(previews types.Previews) string {
+	if previews.Prefix != "" {
+		return previews.Prefix
+	}
+	return DefaultPrefix
+}
+
+// EnvironmentName is the name of the preview environment of a pull request, e.g. pr-123
+func EnvironmentName(previews types.Previews, number int) string {
+	return prefix(previews) + strconv.Itoa(number)
+}
+
+// PullNumber returns the pull request number of a preview environment name, if it is one.
+// The prefix is matched case-insensitively like GitHub matches environment names.
+func PullNumber(previews types.Previews, env string) (int, bool) {
+	p := prefix(previews)
+	if len(env) <= len(p) || !strings.EqualFold(env[:len(p)], p) {
+		return 0, false
+	}
+	number, err := strconv.Atoi(env[len(p):])
+	if err != nil || number <= 0 || strconv.Itoa(number) != env[len(p):] {
+		return 0, false
+	}
+	return number, true
+}
+
+// AllowsRepo reports whether previews are enabled for a repository, matching the repos globs case-insensitively
+func AllowsRepo(previews types.Previews, repo string) bool {
+	if len(previews.Repos) == 0 {
+		return true
+	}
+	for _, pattern := range previews.Repos {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(repo)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Environment returns the options of a preview environment for a pull request from headRef.
+// The environment is always created and only the head branch may deploy to it, replacing any
+// branch policies of the template. The template's maps are copied so the config is not changed.
+func Environment(previews types.Previews, headRef string) types.DeploymentEnvOptions {
+	envOptions := previews.Environment
+	envOptions.Extends = nil
+	envOptions.CreateDeploymentEnv = true
+	envOptions.Variables = maps.Clone(envOptions.Variables)
+	envOptions.Secrets = maps.Clone(envOptions.Secrets)
+	envOptions.DeploymentBranchPolicy = &types.DeploymentBranchPolicy{CustomBranchPolicies: true}
+	envOptions.BranchPolicies = []types.BranchPolicy{{Name: headRef, Type: "branch"}}
+	return envOptions
+}
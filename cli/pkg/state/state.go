@@ -0,0 +1,112 @@
+package state
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// KeyEnv is the environment variable holding the HMAC key used to fingerprint secret values.
+const KeyEnv = "AUTOGIT_STATE_KEY"
+
+// SecretState is what we remember about a secret after it was last written.
+type SecretState struct {
+	Fingerprint string `json:"fingerprint"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+// State holds the fingerprints of every secret AutoGit has written, keyed by org/repo/env/name.
+// GitHub never returns secret values, so this is the only way to tell whether a secret changed.
+type State struct {
+	Secrets map[string]SecretState `json:"secrets"`
+
+	path string
+	key  []byte
+	mu   sync.Mutex
+}
+
+// SecretKey builds the key a secret is stored under in the state file.
+func SecretKey(org, repo, env, secret string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", org, repo, env, secret)
+}
+
+// Load reads the state file at path, returning an empty state if it does not exist yet.
+// The HMAC key is read from AUTOGIT_STATE_KEY.
+func Load(path string) (*State, error) {
+	key := os.Getenv(KeyEnv)
+	if key == "" {
+		return nil, fmt.Errorf("%s environment variable not set", KeyEnv)
+	}
+
+	st := &State{
+		Secrets: make(map[string]SecretState),
+		path:    path,
+		key:     []byte(key),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, fmt.Errorf("failed to decode state file %s: %w", path, err)
+	}
+	if st.Secrets == nil {
+		st.Secrets = make(map[string]SecretState)
+	}
+	return st, nil
+}
+
+// Fingerprint returns the hex encoded HMAC-SHA256 of a secret value.
+func (s *State) Fingerprint(value string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Unchanged reports whether value matches the recorded fingerprint and GitHub's updated_at
+// still matches the one recorded, i.e. nobody touched the secret since we last wrote it.
+func (s *State) Unchanged(key, value, updatedAt string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded, ok := s.Secrets[key]
+	if !ok || updatedAt == "" {
+		return false
+	}
+	return hmac.Equal([]byte(recorded.Fingerprint), []byte(s.Fingerprint(value))) && recorded.UpdatedAt == updatedAt
+}
+
+// Record stores the fingerprint of value along with GitHub's updated_at for the secret.
+func (s *State) Record(key, value, updatedAt string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Secrets[key] = SecretState{
+		Fingerprint: s.Fingerprint(value),
+		UpdatedAt:   updatedAt,
+	}
+}
+
+// Save writes the state back to the file it was loaded from.
+func (s *State) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", s.path, err)
+	}
+	return nil
+}
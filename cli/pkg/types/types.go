@@ -1,24 +1,194 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Used for the fetch Command
+// The top-level org, repos and repoSelectors are the single organization layout; config.Load moves
+// them into Orgs so commands only ever have to walk Orgs.
 type Config struct {
-	Org   string                                     `yaml:"org"`
-	Repos map[string]map[string]DeploymentEnvOptions `yaml:"repos"`
+	Org   string                                     `yaml:"org,omitempty"`
+	Repos map[string]map[string]DeploymentEnvOptions `yaml:"repos,omitempty"`
+	// Orgs configures several organizations in one file, keyed by organization name
+	Orgs map[string]OrgConfig `yaml:"orgs,omitempty"`
+	// RepoSelectors apply environments to every repository they match, expanded into Repos at runtime
+	RepoSelectors []RepoSelector `yaml:"repoSelectors,omitempty"`
+	// Defaults and EnvironmentTemplates are merged into the environments by config.Load
+	Defaults             *DeploymentEnvOptions           `yaml:"defaults,omitempty"`
+	EnvironmentTemplates map[string]DeploymentEnvOptions `yaml:"environmentTemplates,omitempty"`
+	// Include lists further config files, directories or glob patterns, relative to this file
+	Include []string `yaml:"include,omitempty"`
+	// StateFile records secret fingerprints so unchanged secrets are not rewritten
+	StateFile string `yaml:"stateFile,omitempty"`
+	// Freezes block deployments and environment changes during change freezes
+	Freezes []Freeze `yaml:"freezes,omitempty"`
+	// Previews configures per-pull-request preview environments for every organization without its own
+	Previews *Previews `yaml:"previews,omitempty"`
 	// Repos map[string][]string `yaml:"repos"`
 }
+
+// Freeze is a change freeze, either recurring (a cron schedule and a duration) or an absolute start and end.
+// Orgs, Repos and Environments are globs narrowing what it covers, empty meaning everything.
+type Freeze struct {
+	Name         string   `yaml:"name"`
+	Schedule     string   `yaml:"schedule,omitempty"` // cron expression for the start, e.g. "0 15 * * 5"
+	Duration     string   `yaml:"duration,omitempty"` // e.g. 57h
+	Start        string   `yaml:"start,omitempty"`    // e.g. 2025-12-20 or 2025-12-20T15:00
+	End          string   `yaml:"end,omitempty"`
+	Timezone     string   `yaml:"timezone,omitempty"` // IANA name, e.g. Europe/London (default is local time)
+	Orgs         []string `yaml:"orgs,omitempty"`
+	Repos        []string `yaml:"repos,omitempty"`
+	Environments []string `yaml:"environments,omitempty"`
+}
+
+// Previews configures the preview environments created for pull requests by `autogit preview`.
+// Environment is resolved against defaults and environmentTemplates like any other environment.
+type Previews struct {
+	Prefix      string               `yaml:"prefix,omitempty"` // environment name prefix (default "pr-")
+	Repos       []string             `yaml:"repos,omitempty"`  // globs of repositories allowed previews, empty meaning all
+	Environment DeploymentEnvOptions `yaml:"environment"`
+}
+
+// OrgConfig is the configuration of one organization in `orgs:`
+type OrgConfig struct {
+	Repos         map[string]map[string]DeploymentEnvOptions `yaml:"repos,omitempty"`
+	RepoSelectors []RepoSelector                             `yaml:"repoSelectors,omitempty"`
+	// Defaults and EnvironmentTemplates are layered over the top-level ones for this organization only
+	Defaults             *DeploymentEnvOptions           `yaml:"defaults,omitempty"`
+	EnvironmentTemplates map[string]DeploymentEnvOptions `yaml:"environmentTemplates,omitempty"`
+	// APIHost is the API URL for GitHub Enterprise Server, e.g. https://github.example.com/api/v3
+	APIHost string `yaml:"apiHost,omitempty"`
+	// TokenEnv names the environment variable holding the token for this organization (default GITHUB_TOKEN, required with APIHost)
+	TokenEnv string `yaml:"tokenEnv,omitempty"`
+	// Previews overrides the top-level previews for this organization
+	Previews *Previews `yaml:"previews,omitempty"`
+}
+
 type DeploymentEnvOptions struct {
-	CreateDeploymentEnv bool              `yaml:"createDeploymentEnv,omitempty"`
-	CreateVariables     bool              `yaml:"createVariables,omitempty"`
-	Variables           map[string]string `yaml:"variables,omitempty"`
-	CreateSecrets       bool              `yaml:"createSecrets,omitempty"`
-	Secrets             map[string]string `yaml:"secrets,omitempty"`
-	FetchReleases       bool              `yaml:"fetchReleases,omitempty"`
+	Extends             StringList `yaml:"extends,omitempty"`
+	CreateDeploymentEnv bool       `yaml:"createDeploymentEnv,omitempty"`
+	CreateVariables     bool       `yaml:"createVariables,omitempty"`
+	Variables           Values     `yaml:"variables,omitempty"`
+	CreateSecrets       bool       `yaml:"createSecrets,omitempty"`
+	Secrets             Values     `yaml:"secrets,omitempty"`
+	FetchReleases       bool       `yaml:"fetchReleases,omitempty"`
+	// Protection rules applied when the deployment environment is created. Unset rules are left as they are
+	// in GitHub, while waitTimer: 0, preventSelfReview: false and reviewers: [] clear them.
+	WaitTimer              *int                    `yaml:"waitTimer,omitempty"`
+	PreventSelfReview      *bool                   `yaml:"preventSelfReview,omitempty"`
+	Reviewers              []Reviewer              `yaml:"reviewers,omitempty"`
+	DeploymentBranchPolicy *DeploymentBranchPolicy `yaml:"deploymentBranchPolicy,omitempty"`
+	BranchPolicies         []BranchPolicy          `yaml:"branchPolicies,omitempty"`
+	// Workflow dispatched by deploy, e.g. deploy.yml
+	DeployWorkflow string `yaml:"deployWorkflow,omitempty"`
+	// Environment whose successful deployment promote copies into this one, e.g. uat for prod
+	PromoteFrom string `yaml:"promoteFrom,omitempty"`
+}
+
+// Reviewer is a user or team required to approve deployments to an environment
+type Reviewer struct {
+	Type string `yaml:"type" json:"type"` // User or Team
+	ID   int    `yaml:"id" json:"id"`
+	Name string `yaml:"name,omitempty" json:"-"` // login or team slug, for readability only
 }
 
+// DeploymentBranchPolicy restricts which branches can deploy to an environment
+type DeploymentBranchPolicy struct {
+	ProtectedBranches    bool `yaml:"protectedBranches" json:"protected_branches"`
+	CustomBranchPolicies bool `yaml:"customBranchPolicies" json:"custom_branch_policies"`
+}
+
+// BranchPolicy is a branch or tag name pattern allowed to deploy when custom branch policies are used
+type BranchPolicy struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type,omitempty" json:"type,omitempty"` // branch or tag
+}
+
+// RepoSelector applies the same environments to every repository matching Match.
+// Environments configured explicitly under repos take precedence.
+type RepoSelector struct {
+	Match        RepoMatch                       `yaml:"match"`
+	Environments map[string]DeploymentEnvOptions `yaml:"environments"`
+}
+
+// RepoMatch selects repositories of the org. Every criterion given must match.
+type RepoMatch struct {
+	Names            []string          `yaml:"names,omitempty"`  // glob patterns, e.g. svc-*
+	Topics           []string          `yaml:"topics,omitempty"` // repository must have all of these topics
+	Team             string            `yaml:"team,omitempty"`   // team slug with access to the repository
+	CustomProperties map[string]string `yaml:"customProperties,omitempty"`
+	ExcludeArchived  bool              `yaml:"excludeArchived,omitempty"`
+}
+
+// StringList accepts either a single string or a list of strings
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*l = StringList{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// SameValue reports whether a configured variable value matches the one in GitHub. Values are
+// compared exactly, so a trailing newline from a block scalar counts as a difference.
+func SameValue(expected, actual string) bool {
+	return expected == actual
+}
+
+// Values holds variable or secret values by name. Scalars are kept as written (block scalars
+// give multi-line values) while maps and lists are stored as their JSON encoding.
+type Values map[string]string
+
+func (v *Values) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a map of names to values", node.Line)
+	}
+	values := make(Values, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i].Value, node.Content[i+1]
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+		switch value.Kind {
+		case yaml.ScalarNode:
+			if value.Tag != "!!null" {
+				values[name] = value.Value
+			} else {
+				values[name] = ""
+			}
+		case yaml.MappingNode, yaml.SequenceNode:
+			var structured interface{}
+			if err := value.Decode(&structured); err != nil {
+				return fmt.Errorf("line %d: %s: %w", value.Line, name, err)
+			}
+			encoded, err := json.Marshal(structured)
+			if err != nil {
+				return fmt.Errorf("line %d: %s cannot be encoded as JSON: %w", value.Line, name, err)
+			}
+			values[name] = string(encoded)
+		default:
+			return fmt.Errorf("line %d: unsupported value for %s", value.Line, name)
+		}
+	}
+	*v = values
+	return nil
+}
+
+// SecretPlaceholder is written instead of secret values, which GitHub never returns
+const SecretPlaceholder = "<set-me>"
+
 type EnvCheck struct {
 	Title string `json:"title"`
 }
@@ -32,6 +202,7 @@ type OutputData struct {
 type EnvData struct {
 	DeploymentID  int    `yaml:"deployment_id,omitempty"`
 	Ref           string `yaml:"ref,omitempty"`
+	SHA           string `yaml:"sha,omitempty"`
 	Description   string `yaml:"description,omitempty"`
 	CreatedAt     string `yaml:"created_at,omitempty"`
 	Status        string `yaml:"status,omitempty"`
@@ -56,11 +227,81 @@ type WorkflowData struct {
 type Deployment struct {
 	ID          int       `json:"id"`
 	Ref         string    `json:"ref"`
+	SHA         string    `json:"sha"`
 	Description string    `json:"description"`
 	CreatedAt   time.Time `json:"created_at"`
 	StatusesURL string    `json:"statuses_url"`
 	Timestamp   int64     `json:"timestamp"`
 	Environment string    `json:"environment"`
+	// ProductionEnvironment deployments are left alone by auto_inactive
+	ProductionEnvironment bool `json:"production_environment"`
+}
+
+// DeploymentStatus is a state change of a deployment, the newest one is its current state
+type DeploymentStatus struct {
+	ID             int       `json:"id"`
+	State          string    `json:"state"`
+	Description    string    `json:"description"`
+	Environment    string    `json:"environment"`
+	EnvironmentURL string    `json:"environment_url"`
+	LogURL         string    `json:"log_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	Creator        struct {
+		Login string `json:"login"`
+	} `json:"creator"`
+	// AutoInactive is only sent when set: false keeps GitHub from marking older deployments to the
+	// environment inactive when this status is success
+	AutoInactive *bool `json:"auto_inactive,omitempty"`
+}
+
+// Environment is a deployment environment as returned by GitHub
+type Environment struct {
+	ID                     int                     `json:"id"`
+	Name                   string                  `json:"name"`
+	CreatedAt              string                  `json:"created_at"`
+	UpdatedAt              string                  `json:"updated_at"`
+	ProtectionRules        []ProtectionRule        `json:"protection_rules"`
+	DeploymentBranchPolicy *DeploymentBranchPolicy `json:"deployment_branch_policy"`
+}
+
+// ProtectionRule is one of the wait_timer, required_reviewers or branch_policy rules of an environment
+type ProtectionRule struct {
+	Type              string `json:"type"`
+	WaitTimer         int    `json:"wait_timer"`
+	PreventSelfReview bool   `json:"prevent_self_review"`
+	Reviewers         []struct {
+		Type     string `json:"type"`
+		Reviewer struct {
+			ID    int    `json:"id"`
+			Login string `json:"login"`
+			Slug  string `json:"slug"`
+		} `json:"reviewer"`
+	} `json:"reviewers"`
+}
+
+// Variable is an environment variable as returned by GitHub
+type Variable struct {
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Repository is the subset of a GitHub repository AutoGit uses
+type Repository struct {
+	Name     string   `json:"name"`
+	FullName string   `json:"full_name"`
+	Archived bool     `json:"archived"`
+	Topics   []string `json:"topics"`
+	// Filled in from the custom properties API when a selector needs them
+	CustomProperties map[string]string `json:"-"`
+}
+
+// SecretMetadata is what GitHub returns for an existing secret (never the value)
+type SecretMetadata struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
 }
 
 // Used for the secrets Command
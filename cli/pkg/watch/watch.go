@@ -0,0 +1,106 @@
+// Package watch turns repeated snapshots of deployments and their statuses into change events.
+package watch
+
+import (
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Event is a new deployment or a status change of a deployment
+type Event struct {
+	Time           time.Time `json:"time"`
+	Type           string    `json:"type"` // deployment or status
+	Org            string    `json:"org"`
+	Repo           string    `json:"repo"`
+	Environment    string    `json:"environment"`
+	DeploymentID   int       `json:"deployment_id"`
+	Ref            string    `json:"ref"`
+	SHA            string    `json:"sha"`
+	State          string    `json:"state,omitempty"`
+	Description    string    `json:"description,omitempty"`
+	Creator        string    `json:"creator,omitempty"`
+	LogURL         string    `json:"log_url,omitempty"`
+	EnvironmentURL string    `json:"environment_url,omitempty"`
+}
+
+// Tracker remembers what was seen so that only changes are reported.
+// The first snapshot of a repository is a baseline and produces no events.
+type Tracker struct {
+	deployments map[int]types.Deployment
+	statuses    map[int]int // deployment ID to the ID of its newest status
+	baselined   map[string]bool
+	fresh       map[int]bool // deployments created after the baseline
+}
+
+// NewTracker returns an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		deployments: make(map[int]types.Deployment),
+		statuses:    make(map[int]int),
+		baselined:   make(map[string]bool),
+		fresh:       make(map[int]bool),
+	}
+}
+
+// Deployments records a snapshot of a repository's deployments and returns the new ones, oldest first
+func (t *Tracker) Deployments(org, repo string, deployments []types.Deployment) []Event {
+	key := org + "/" + repo
+	baseline := !t.baselined[key]
+	t.baselined[key] = true
+
+	var events []Event
+	for i := len(deployments) - 1; i >= 0; i-- {
+		deployment := deployments[i]
+		if _, ok := t.deployments[deployment.ID]; ok {
+			continue
+		}
+		t.deployments[deployment.ID] = deployment
+		if !baseline {
+			t.fresh[deployment.ID] = true
+			events = append(events, Event{
+				Time:         deployment.CreatedAt,
+				Type:         "deployment",
+				Org:          org,
+				Repo:         repo,
+				Environment:  deployment.Environment,
+				DeploymentID: deployment.ID,
+				Ref:          deployment.Ref,
+				SHA:          deployment.SHA,
+				Description:  deployment.Description,
+			})
+		}
+	}
+	return events
+}
+
+// Statuses records the statuses of a deployment, newest first, and returns an event when the newest one changed.
+// The statuses first seen for a deployment of the baseline are not reported.
+func (t *Tracker) Statuses(org, repo string, deploymentID int, statuses []types.DeploymentStatus) []Event {
+	if len(statuses) == 0 {
+		return nil
+	}
+	newest := statuses[0]
+	previous, seen := t.statuses[deploymentID]
+	t.statuses[deploymentID] = newest.ID
+	if previous == newest.ID || (!seen && !t.fresh[deploymentID]) {
+		return nil
+	}
+
+	deployment := t.deployments[deploymentID]
+	return []Event{{
+		Time:           newest.CreatedAt,
+		Type:           "status",
+		Org:            org,
+		Repo:           repo,
+		Environment:    deployment.Environment,
+		DeploymentID:   deploymentID,
+		Ref:            deployment.Ref,
+		SHA:            deployment.SHA,
+		State:          newest.State,
+		Description:    newest.Description,
+		Creator:        newest.Creator.Login,
+		LogURL:         newest.LogURL,
+		EnvironmentURL: newest.EnvironmentURL,
+	}}
+}
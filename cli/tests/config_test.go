@@ -0,0 +1,388 @@
+package api_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+)
+
+// writeConfig writes a config file into a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+// ptr returns a pointer to v, for the optional protection rules
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// Writing test to check multi-line and structured values are loaded as strings
+func TestLoadValues(t *testing.T) {
+	path := writeConfig(t, `
+org: MarkDevOps
+repos:
+  AutoGit:
+    Dev:
+      createVariables: true
+      variables:
+        PLAIN: "say \"hi\""
+        SCRIPT: |
+          echo one
+          echo two
+        SETTINGS:
+          LogLevel: debug
+          retries: 3
+        HOSTS:
+          - a.example.com
+          - b.example.com
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	variables := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["Dev"].Variables
+	expected := map[string]string{
+		"PLAIN":    `say "hi"`,
+		"SCRIPT":   "echo one\necho two\n",
+		"SETTINGS": `{"LogLevel":"debug","retries":3}`,
+		"HOSTS":    `["a.example.com","b.example.com"]`,
+	}
+	for name, want := range expected {
+		if got := variables[name]; got != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+}
+
+// Writing test to check defaults and environment templates are merged into environments
+func TestLoadInheritance(t *testing.T) {
+	path := writeConfig(t, `
+org: MarkDevOps
+defaults:
+  createDeploymentEnv: true
+  createVariables: true
+  variables:
+    REGION: eu-west-1
+    DEBUG: "false"
+environmentTemplates:
+  base:
+    waitTimer: 5
+    variables:
+      TIER: standard
+  prod-template:
+    extends: base
+    createSecrets: true
+    variables:
+      TIER: critical
+repos:
+  AutoGit:
+    dev:
+      variables:
+        DEBUG: "true"
+    prod:
+      extends: prod-template
+      createDeploymentEnv: false
+      variables:
+        DEBUG: ~
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	dev := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["dev"]
+	if !dev.CreateDeploymentEnv || !dev.CreateVariables || dev.CreateSecrets {
+		t.Errorf("Expected dev to inherit the defaults, got %+v", dev)
+	}
+	if dev.Variables["DEBUG"] != "true" || dev.Variables["REGION"] != "eu-west-1" {
+		t.Errorf("Expected dev variables to be merged, got %v", dev.Variables)
+	}
+
+	prod := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["prod"]
+	if prod.CreateDeploymentEnv {
+		t.Errorf("Expected prod to override createDeploymentEnv with false")
+	}
+	if !prod.CreateSecrets || prod.WaitTimer == nil || *prod.WaitTimer != 5 {
+		t.Errorf("Expected prod to inherit from prod-template and base, got %+v", prod)
+	}
+	if prod.Variables["TIER"] != "critical" || prod.Variables["REGION"] != "eu-west-1" {
+		t.Errorf("Expected prod variables to be merged, got %v", prod.Variables)
+	}
+	if _, ok := prod.Variables["DEBUG"]; ok {
+		t.Errorf("Expected null to remove the inherited DEBUG variable")
+	}
+	if len(prod.Extends) != 0 || cfg.Defaults != nil || cfg.EnvironmentTemplates != nil {
+		t.Errorf("Expected inheritance settings to be resolved away")
+	}
+}
+
+// Writing test to check templates extending each other in a loop are rejected
+func TestLoadInheritanceCycle(t *testing.T) {
+	path := writeConfig(t, `
+org: MarkDevOps
+environmentTemplates:
+  a:
+    extends: b
+  b:
+    extends: a
+repos:
+  AutoGit:
+    dev:
+      extends: a
+`)
+
+	if _, err := config.Load(path); err == nil {
+		t.Errorf("Expected an error for templates extending each other")
+	}
+}
+
+// Writing test to check typos and names GitHub would reject are reported with their position
+func TestLoadValidation(t *testing.T) {
+	path := writeConfig(t, `org: MarkDevOps
+repos:
+  AutoGit:
+    Dev:
+      createVariable: true
+      secret:
+        a: b
+      variables:
+        GITHUB_TOKEN: x
+        1st: x
+        my-var: x
+        Var1: x
+        VAR1: y
+        EMPTY: ""
+    dev:
+      createDeploymentEnv: true
+`)
+
+	_, err := config.Load(path)
+	var validationErrors config.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+
+	expected := []struct {
+		line    int
+		message string
+	}{
+		{5, `unknown key "createVariable" (did you mean "createVariables"?)`},
+		{6, `unknown key "secret" (did you mean "secrets"?)`},
+		{9, "reserved GITHUB_ prefix"},
+		{10, "may not start with a number"},
+		{11, "may only contain letters, numbers and underscores"},
+		{13, `only differs by case from "Var1"`},
+		{14, "has an empty value"},
+		{15, `only differs by case from "Dev"`},
+	}
+	for _, want := range expected {
+		found := false
+		for _, got := range validationErrors {
+			if got.Line == want.line && strings.Contains(got.Message, want.message) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected error on line %d containing %q, got:\n%v", want.line, want.message, err)
+		}
+	}
+	if len(validationErrors) != len(expected) {
+		t.Errorf("Expected %d errors, got %d:\n%v", len(expected), len(validationErrors), err)
+	}
+}
+
+// Writing test to check mis-cased keys are reported with their position and the right key
+func TestLoadKeyCase(t *testing.T) {
+	path := writeConfig(t, `org: MarkDevOps
+repos:
+  AutoGit:
+    Dev:
+      createVariables: true
+      CreateVariables: true
+`)
+
+	_, err := config.Load(path)
+	var validationErrors config.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("Expected validation errors, got %v", err)
+	}
+	if len(validationErrors) != 1 || validationErrors[0].Line != 6 || validationErrors[0].Column != 7 ||
+		!strings.Contains(validationErrors[0].Message, `did you mean "createVariables"? keys are case-sensitive`) {
+		t.Errorf("Expected one case hint for line 6, got:\n%v", err)
+	}
+}
+
+// Writing test to check protection rules set to zero values are kept apart from unset ones
+func TestLoadClearedRules(t *testing.T) {
+	path := writeConfig(t, `org: MarkDevOps
+repos:
+  AutoGit:
+    prod:
+      waitTimer: 0
+      reviewers: []
+    dev:
+      createDeploymentEnv: true
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	prod := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["prod"]
+	if prod.WaitTimer == nil || *prod.WaitTimer != 0 || prod.Reviewers == nil {
+		t.Errorf("Expected prod to clear waitTimer and reviewers, got %+v", prod)
+	}
+	dev := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["dev"]
+	if dev.WaitTimer != nil || dev.PreventSelfReview != nil || dev.Reviewers != nil {
+		t.Errorf("Expected dev to leave the rules unset, got %+v", dev)
+	}
+}
+
+// Writing test to check the published JSON Schema is up to date with the config types
+func TestSchemaUpToDate(t *testing.T) {
+	published, err := os.ReadFile("../config.schema.json")
+	if err != nil {
+		t.Fatalf("failed to read config.schema.json: %v", err)
+	}
+	schema, err := config.Schema()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.TrimSpace(string(published)) != strings.TrimSpace(string(schema)) {
+		t.Errorf("config.schema.json is out of date, regenerate it with: go run . config schema > config.schema.json")
+	}
+}
+
+// Writing test to check several organizations can be configured alongside the single org layout
+func TestLoadOrgs(t *testing.T) {
+	path := writeConfig(t, `
+org: MarkDevOps
+defaults:
+  createDeploymentEnv: true
+repos:
+  AutoGit:
+    dev: {}
+orgs:
+  OtherOrg:
+    apiHost: https://github.example.com/api/v3
+    tokenEnv: OTHER_ORG_TOKEN
+    defaults:
+      createVariables: true
+    repos:
+      Service:
+        prod: {}
+`)
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	names := config.OrgNames(cfg)
+	if len(names) != 2 || names[0] != "MarkDevOps" || names[1] != "OtherOrg" {
+		t.Fatalf("Expected both organizations, got %v", names)
+	}
+	if cfg.Org != "" || cfg.Repos != nil {
+		t.Errorf("Expected the single org layout to be moved into orgs")
+	}
+	if dev := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["dev"]; !dev.CreateDeploymentEnv || dev.CreateVariables {
+		t.Errorf("Expected only the top-level defaults for MarkDevOps, got %+v", dev)
+	}
+	other := cfg.Orgs["OtherOrg"]
+	if prod := other.Repos["Service"]["prod"]; !prod.CreateDeploymentEnv || !prod.CreateVariables {
+		t.Errorf("Expected the org defaults layered over the top-level ones, got %+v", prod)
+	}
+	if other.APIHost != "https://github.example.com/api/v3" || other.TokenEnv != "OTHER_ORG_TOKEN" {
+		t.Errorf("Expected the API host and token of OtherOrg, got %+v", other)
+	}
+
+	conflicting := writeConfig(t, `
+org: MarkDevOps
+repos:
+  AutoGit:
+    dev: {}
+orgs:
+  MarkDevOps:
+    repos:
+      AutoGit:
+        test: {}
+`)
+	if _, err := config.Load(conflicting); err == nil {
+		t.Errorf("Expected an error for a repository configured twice")
+	}
+}
+
+// Writing test to check a config split over a directory and includes is merged into one config
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": `
+org: MarkDevOps
+include:
+  - ../shared/templates.yaml
+defaults:
+  createDeploymentEnv: true
+`,
+		"teams/platform.yaml": `
+repos:
+  AutoGit:
+    dev:
+      extends: standard
+`,
+		"teams/payments.yml": `
+org: MarkDevOps
+repos:
+  AutoGit:
+    prod: {}
+  Payments:
+    dev: {}
+`,
+		"../shared/templates.yaml": `
+environmentTemplates:
+  standard:
+    variables:
+      TIER: standard
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, "config", name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := config.Load(filepath.Join(dir, "config"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repos := cfg.Orgs["MarkDevOps"].Repos
+	if dev := repos["AutoGit"]["dev"]; !dev.CreateDeploymentEnv || dev.Variables["TIER"] != "standard" {
+		t.Errorf("Expected AutoGit/dev to use the included template and defaults, got %+v", dev)
+	}
+	if _, ok := repos["AutoGit"]["prod"]; !ok {
+		t.Errorf("Expected AutoGit environments from both team files")
+	}
+	if _, ok := repos["Payments"]["dev"]; !ok {
+		t.Errorf("Expected Payments from the payments team file")
+	}
+
+	conflict := filepath.Join(dir, "config", "teams", "conflict.yaml")
+	os.WriteFile(conflict, []byte("repos:\n  AutoGit:\n    dev: {}\n"), 0644)
+	_, err = config.Load(filepath.Join(dir, "config"))
+	if err == nil || !strings.Contains(err.Error(), "repos.AutoGit.dev is already defined") {
+		t.Errorf("Expected a conflict for AutoGit/dev, got %v", err)
+	}
+}
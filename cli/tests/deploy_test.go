@@ -0,0 +1,195 @@
+package api_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/deploy"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// statusHistory serves deployment statuses, newest first, from a map of deployment IDs to states
+func statusHistory(states map[int][]string) deploy.StatusFetcher {
+	return func(deploymentID int) ([]types.DeploymentStatus, error) {
+		history, ok := states[deploymentID]
+		if !ok {
+			return nil, fmt.Errorf("unexpected deployment %d", deploymentID)
+		}
+		var statuses []types.DeploymentStatus
+		for _, state := range history {
+			statuses = append(statuses, types.DeploymentStatus{State: state})
+		}
+		return statuses, nil
+	}
+}
+
+// Writing test to check the current deployment of an environment skips failed and unfinished deployments
+func TestLastSuccessful(t *testing.T) {
+	deployments := []types.Deployment{{ID: 4, Ref: "v4"}, {ID: 3, Ref: "v3"}, {ID: 2, Ref: "v2"}, {ID: 1, Ref: "v1"}}
+	statuses := statusHistory(map[int][]string{
+		4: {"in_progress", "queued"},
+		3: {"failure", "in_progress"},
+		2: {"success", "in_progress"},
+	})
+
+	deployment, err := deploy.LastSuccessful(deployments, statuses)
+	if err != nil || deployment.Ref != "v2" {
+		t.Errorf("Expected v2, the newest successful deployment, got %v, %v", deployment.Ref, err)
+	}
+
+	if _, err := deploy.LastSuccessful(deployments[:2], statuses); err == nil {
+		t.Errorf("Expected an error without a successful deployment")
+	}
+}
+
+// Writing test to check a dispatch follows its own run and not a concurrent one
+func TestDispatchedRun(t *testing.T) {
+	run := func(id int, branch, actor, title string) api.WorkflowRun {
+		r := api.WorkflowRun{ID: id, HeadBranch: branch, DisplayTitle: title}
+		r.Actor.Login = actor
+		return r
+	}
+	runs := []api.WorkflowRun{
+		run(4, "main", "someone-else", "Deploy prod (autogit-2)"),
+		run(3, "release/1.2", "octocat", "Deploy uat (autogit-1)"),
+		run(2, "main", "octocat", "Deploy dev (autogit-3)"),
+		run(1, "main", "octocat", "Deploy dev"),
+	}
+	seen := map[int]bool{1: true}
+
+	cases := []struct {
+		ref, actor, correlationID string
+		want                      int
+	}{
+		{"main", "octocat", "", 2},
+		{"refs/heads/main", "OctoCat", "", 2},
+		{"main", "", "", 4},
+		{"release/1.2", "octocat", "", 3},
+		{"main", "octocat", "autogit-1", 3},
+		{"main", "octocat", "autogit-9", 0},
+		{"develop", "octocat", "", 0},
+	}
+	for _, c := range cases {
+		got := deploy.DispatchedRun(runs, seen, c.ref, c.actor, c.correlationID)
+		switch {
+		case c.want == 0 && got != nil:
+			t.Errorf("%+v: Expected no run, got #%d", c, got.ID)
+		case c.want != 0 && (got == nil || got.ID != c.want):
+			t.Errorf("%+v: Expected run %d, got %v", c, c.want, got)
+		}
+	}
+}
+
+// Writing test to check review only offers environments the user can approve and reads the picker answer
+func TestReviewSelection(t *testing.T) {
+	pending := func(env string, canApprove bool) api.PendingDeployment {
+		var deployment api.PendingDeployment
+		deployment.Environment.Name = env
+		deployment.CurrentUserCanApprove = canApprove
+		return deployment
+	}
+	deployments := []api.PendingDeployment{pending("prod", true), pending("uat", true), pending("staging", false)}
+
+	var names []string
+	for _, deployment := range deploy.Reviewable("web", deployments, config.Filter{Exclude: []string{"web/uat"}}) {
+		names = append(names, deployment.Environment.Name)
+	}
+	if len(names) != 1 || names[0] != "prod" {
+		t.Errorf("Expected only prod to be reviewable, got %v", names)
+	}
+
+	cases := map[string]string{
+		"1,3":     "[0 2]",
+		" 2 1 ":   "[1 0]",
+		"ALL":     "[0 1 2]",
+		"":        "[]",
+		"0,2,x,4": "[1]",
+	}
+	for answer, want := range cases {
+		selected, _ := deploy.ParseSelection(answer, 3)
+		if got := fmt.Sprint(selected); got != want {
+			t.Errorf("%q: Expected %s, got %s", answer, want, got)
+		}
+	}
+	if _, invalid := deploy.ParseSelection("0,2,x,4", 3); fmt.Sprint(invalid) != "[0 x 4]" {
+		t.Errorf("Expected 0, x and 4 to be invalid, got %v", invalid)
+	}
+}
+
+// Writing test to check a promotion deploys from --from or promoteFrom and refuses a ref that moved
+func TestPromotionPlanning(t *testing.T) {
+	cases := []struct {
+		from, promoteFrom, want string
+		fails                   bool
+	}{
+		{"test", "", "test", false},
+		{"", "uat", "uat", false},
+		{"uat", "uat", "uat", false},
+		{"test", "uat", "", true},
+		{"", "", "", true},
+	}
+	for _, c := range cases {
+		got, err := deploy.PromotionSource(c.from, "prod", types.DeploymentEnvOptions{PromoteFrom: c.promoteFrom})
+		if (err != nil) != c.fails || got != c.want {
+			t.Errorf("%+v: Expected %q, got %q, %v", c, c.want, got, err)
+		}
+	}
+
+	if deploy.RefMoved("abc123", "abc123") || deploy.RefMoved("", "abc123") {
+		t.Errorf("Expected an unchanged or unknown deployed commit not to count as moved")
+	}
+	if !deploy.RefMoved("abc123", "def456") {
+		t.Errorf("Expected a ref pointing at another commit to count as moved")
+	}
+}
+
+// Writing test to check rollback picks the last successful deployment of another commit
+func TestRollbackTarget(t *testing.T) {
+	deployments := []types.Deployment{
+		{ID: 5, Ref: "v5", SHA: "e"},
+		{ID: 4, Ref: "main", SHA: "e"},
+		{ID: 3, Ref: "v4", SHA: "d"},
+		{ID: 2, Ref: "v3", SHA: "c"},
+		{ID: 1, Ref: "v2", SHA: "b"},
+	}
+	statuses := statusHistory(map[int][]string{
+		3: {"failure", "in_progress"},
+		2: {"inactive", "success", "in_progress"},
+		1: {"inactive", "success"},
+	})
+
+	current, previous, err := deploy.RollbackTarget(deployments, statuses)
+	if err != nil || current.ID != 5 || previous.Ref != "v3" {
+		t.Errorf("Expected v5 to roll back to v3, got %s to %s, %v", current.Ref, previous.Ref, err)
+	}
+	if _, _, err := deploy.RollbackTarget(deployments[:3], statuses); err == nil {
+		t.Errorf("Expected an error without an earlier successful deployment")
+	}
+	if _, _, err := deploy.RollbackTarget(nil, statuses); err == nil {
+		t.Errorf("Expected an error without deployments")
+	}
+
+	cases := map[string]bool{
+		"success":              true,
+		"inactive,success":     true,
+		"inactive,in_progress": false,
+		"failure,success":      false,
+		"in_progress,queued":   false,
+		"inactive":             false,
+	}
+	for history, want := range cases {
+		var deploymentStatuses []types.DeploymentStatus
+		for _, state := range strings.Split(history, ",") {
+			deploymentStatuses = append(deploymentStatuses, types.DeploymentStatus{State: state})
+		}
+		if got := deploy.Succeeded(deploymentStatuses); got != want {
+			t.Errorf("%s: Expected %v, got %v", history, want, got)
+		}
+	}
+	if deploy.Succeeded(nil) {
+		t.Errorf("Expected a deployment without statuses not to have succeeded")
+	}
+}
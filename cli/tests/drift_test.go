@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/drift"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check configured variables are compared with GitHub case-insensitively
+func TestCompareVariables(t *testing.T) {
+	expected := types.Values{"var1": "same", "var2": "new", "var3": "missing"}
+	actual := []types.Variable{
+		{Name: "VAR1", Value: "same"},
+		{Name: "VAR2", Value: "old"},
+		{Name: "VAR4", Value: "clicked in the UI"},
+	}
+
+	statuses := make(map[string]string)
+	for _, result := range drift.CompareVariables("repo", "dev", expected, actual) {
+		statuses[result.Name] = result.Status
+	}
+
+	want := map[string]string{
+		"var1": drift.Unchanged,
+		"var2": drift.Drifted,
+		"var3": drift.Missing,
+		"VAR4": drift.Unmanaged,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("%s: expected %s, got %s", name, status, statuses[name])
+		}
+	}
+}
+
+// Writing test to check only configured environment settings are compared
+func TestCompareEnvironment(t *testing.T) {
+	expected := types.DeploymentEnvOptions{WaitTimer: ptr(10)}
+	actual := types.DeploymentEnvOptions{WaitTimer: ptr(10), PreventSelfReview: ptr(true)}
+
+	results := drift.CompareEnvironment("repo", "prod", expected, actual, true)
+	if drift.HasDrift(results, false) {
+		t.Errorf("Expected no drift, got %v", results)
+	}
+
+	results = drift.CompareEnvironment("repo", "prod", expected, actual, false)
+	if len(results) != 1 || results[0].Status != drift.Missing {
+		t.Errorf("Expected missing environment, got %v", results)
+	}
+
+	// Rules cleared in the config compare against rules GitHub no longer reports
+	cleared := types.DeploymentEnvOptions{WaitTimer: ptr(0), PreventSelfReview: ptr(false), Reviewers: []types.Reviewer{}}
+	if results := drift.CompareEnvironment("repo", "prod", cleared, types.DeploymentEnvOptions{}, true); drift.HasDrift(results, false) {
+		t.Errorf("Expected cleared rules to match an environment without them, got %v", results)
+	}
+	if results := drift.CompareEnvironment("repo", "prod", cleared, actual, true); !drift.HasDrift(results, false) {
+		t.Errorf("Expected cleared rules to drift from an environment with them, got %v", results)
+	}
+
+	unmanaged := []drift.Result{{Status: drift.Unchanged}, {Status: drift.Unmanaged}}
+	if !drift.HasDrift(unmanaged, false) || drift.HasDrift(unmanaged, true) {
+		t.Errorf("Expected unmanaged items to only count as drift when not ignored")
+	}
+}
@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/envfile"
+)
+
+// Writing test to check dotenv files are parsed with quotes, comments and multi-line values
+func TestParseDotenv(t *testing.T) {
+	values, err := envfile.ParseDotenv([]byte(`# settings
+export LOG_LEVEL=debug # inline comment
+EMPTY=
+URL = https://example.com/#anchor
+SINGLE='keep \n and # as is'
+DOUBLE="line1\nline2 \"quoted\""
+CERT="-----BEGIN-----
+abc
+-----END-----"
+`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{
+		"LOG_LEVEL": "debug",
+		"EMPTY":     "",
+		"URL":       "https://example.com/#anchor",
+		"SINGLE":    `keep \n and # as is`,
+		"DOUBLE":    "line1\nline2 \"quoted\"",
+		"CERT":      "-----BEGIN-----\nabc\n-----END-----",
+	}
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("Expected %s to be %q, got %q", key, value, values[key])
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Expected %d values, got %v", len(expected), values)
+	}
+
+	if _, err := envfile.ParseDotenv([]byte("CERT=\"never closed\n")); err == nil {
+		t.Errorf("Expected an error for an unterminated value")
+	}
+	if _, err := envfile.ParseDotenv([]byte("not a pair\n")); err == nil {
+		t.Errorf("Expected an error for a line without =")
+	}
+}
+
+// Writing test to check flat JSON values are read as strings
+func TestParseJSON(t *testing.T) {
+	values, err := envfile.ParseJSON([]byte(`{"NAME": "api", "REPLICAS": 3, "DEBUG": false, "HOSTS": ["a", "b"]}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if values["NAME"] != "api" || values["REPLICAS"] != "3" || values["DEBUG"] != "false" || values["HOSTS"] != `["a","b"]` {
+		t.Errorf("Unexpected values: %v", values)
+	}
+}
+
+// Writing test to check loaded values are written back into the config file
+func TestSetEnvironmentValues(t *testing.T) {
+	path := writeConfig(t, `org: MarkDevOps
+repos:
+  AutoGit:
+    # keep me
+    dev:
+      createVariables: false
+      variables:
+        LOG_LEVEL: info
+`)
+
+	_, err := config.SetEnvironmentValues(path, "MarkDevOps", "AutoGit", "dev",
+		map[string]string{"LOG_LEVEL": "debug", "SCRIPT": "a\nb"}, map[string]string{"TOKEN": "s3cr3t"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the saved config: %v", err)
+	}
+	dev := cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["dev"]
+	if dev.Variables["LOG_LEVEL"] != "debug" || dev.Variables["SCRIPT"] != "a\nb" || dev.Secrets["TOKEN"] != "s3cr3t" {
+		t.Errorf("Unexpected values: %v %v", dev.Variables, dev.Secrets)
+	}
+	if dev.CreateVariables || !dev.CreateSecrets {
+		t.Errorf("Expected createVariables to be kept and createSecrets to be switched on")
+	}
+	data, _ := os.ReadFile(path)
+	if !bytes.Contains(data, []byte("# keep me")) {
+		t.Errorf("Expected comments to be kept:\n%s", data)
+	}
+}
+
+// Writing test to check saved values go to the included file defining the environment
+func TestSetEnvironmentValuesInclude(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "config.yaml")
+	included := filepath.Join(dir, "autogit.yaml")
+	if err := os.WriteFile(main, []byte("org: MarkDevOps\ninclude: [autogit.yaml]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(included, []byte("repos:\n  AutoGit:\n    dev:\n      createVariables: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := config.SetEnvironmentValues(main, "MarkDevOps", "AutoGit", "dev", map[string]string{"LOG_LEVEL": "debug"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if written != included {
+		t.Errorf("Expected the values to be written to %s, got %s", included, written)
+	}
+	cfg, err := config.Load(main)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the saved config: %v", err)
+	}
+	if cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["dev"].Variables["LOG_LEVEL"] != "debug" {
+		t.Errorf("Unexpected values: %v", cfg.Orgs["MarkDevOps"].Repos["AutoGit"]["dev"].Variables)
+	}
+
+	if _, err := config.SetEnvironmentValues(dir, "MarkDevOps", "AutoGit", "dev", map[string]string{"A": "b"}, nil); err == nil {
+		t.Errorf("Expected saving to a config directory to be refused")
+	}
+	if _, err := config.SetEnvironmentValues(filepath.Join(dir, "*.yaml"), "MarkDevOps", "AutoGit", "dev", map[string]string{"A": "b"}, nil); err == nil {
+		t.Errorf("Expected saving to a config pattern to be refused")
+	}
+}
+
+// Writing test to check exported variables follow the Actions precedence and read back unchanged
+func TestMergeAndFormat(t *testing.T) {
+	merged := envfile.Merge(
+		map[string]string{"REGION": "eu", "LOG_LEVEL": "warn"},
+		map[string]string{"log_level": "info", "TEAM": "platform"},
+		map[string]string{"LOG_LEVEL": "debug", "SCRIPT": "echo \"hi\"\n$HOME # not a comment"},
+	)
+	if len(merged) != 4 || merged["LOG_LEVEL"] != "debug" || merged["REGION"] != "eu" {
+		t.Errorf("Unexpected merged values: %v", merged)
+	}
+
+	data, err := envfile.Format(merged, "dotenv")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	parsed, err := envfile.ParseDotenv(data)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing the export:\n%s\n%v", data, err)
+	}
+	for name, value := range merged {
+		if parsed[name] != value {
+			t.Errorf("Expected %s to round-trip as %q, got %q", name, value, parsed[name])
+		}
+	}
+
+	if _, err := envfile.Format(merged, "toml"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
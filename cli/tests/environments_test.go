@@ -0,0 +1,53 @@
+package api_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/api"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check an environment from the GitHub API maps to the config options that create it
+func TestMapEnvironmentOptions(t *testing.T) {
+	payload := `{
+		"name": "prod",
+		"protection_rules": [
+			{"type": "wait_timer", "wait_timer": 30},
+			{"type": "required_reviewers", "prevent_self_review": true, "reviewers": [
+				{"type": "User", "reviewer": {"id": 1, "login": "octocat"}},
+				{"type": "Team", "reviewer": {"id": 2, "slug": "platform"}}
+			]},
+			{"type": "branch_policy"}
+		],
+		"deployment_branch_policy": {"protected_branches": false, "custom_branch_policies": true}
+	}`
+
+	var environment types.Environment
+	if err := json.Unmarshal([]byte(payload), &environment); err != nil {
+		t.Fatalf("failed to decode environment: %v", err)
+	}
+
+	envOptions := api.MapEnvironmentOptions(environment)
+	if !envOptions.CreateDeploymentEnv {
+		t.Errorf("Expected createDeploymentEnv to be true")
+	}
+	if envOptions.WaitTimer == nil || *envOptions.WaitTimer != 30 {
+		t.Errorf("Expected wait timer 30, got %v", envOptions.WaitTimer)
+	}
+	if envOptions.PreventSelfReview == nil || !*envOptions.PreventSelfReview {
+		t.Errorf("Expected preventSelfReview to be true")
+	}
+	expected := []types.Reviewer{{Type: "User", ID: 1, Name: "octocat"}, {Type: "Team", ID: 2, Name: "platform"}}
+	if len(envOptions.Reviewers) != len(expected) {
+		t.Fatalf("Expected %d reviewers, got %d", len(expected), len(envOptions.Reviewers))
+	}
+	for i, reviewer := range expected {
+		if envOptions.Reviewers[i] != reviewer {
+			t.Errorf("Expected reviewer %v, got %v", reviewer, envOptions.Reviewers[i])
+		}
+	}
+	if envOptions.DeploymentBranchPolicy == nil || !envOptions.DeploymentBranchPolicy.CustomBranchPolicies {
+		t.Errorf("Expected custom branch policies, got %v", envOptions.DeploymentBranchPolicy)
+	}
+}
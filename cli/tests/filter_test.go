@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check the filter flags narrow the config down to the selected resources
+func TestFilterApply(t *testing.T) {
+	cfg := types.Config{Orgs: map[string]types.OrgConfig{
+		"MarkDevOps": {Repos: map[string]map[string]types.DeploymentEnvOptions{
+			"svc-orders": {
+				"prod": {Variables: types.Values{"LOG_LEVEL": "warn", "DEBUG": "false"}, Secrets: types.Values{"TOKEN": "x"}},
+				"dev":  {Variables: types.Values{"LOG_LEVEL": "debug"}},
+			},
+			"svc-legacy": {"prod": {Variables: types.Values{"LOG_LEVEL": "warn"}}},
+			"website":    {"prod": {Variables: types.Values{"LOG_LEVEL": "warn"}}},
+		}},
+	}}
+
+	filter := config.Filter{
+		Repos:   []string{"SVC-*"},
+		Envs:    []string{"prod"},
+		Names:   []string{"log_*", "TOKEN"},
+		Exclude: []string{"svc-legacy"},
+	}
+	repos := filter.Apply(cfg).Orgs["MarkDevOps"].Repos
+
+	if len(repos) != 1 {
+		t.Fatalf("Expected only svc-orders to be selected, got %v", repos)
+	}
+	prod, ok := repos["svc-orders"]["prod"]
+	if !ok || len(repos["svc-orders"]) != 1 {
+		t.Fatalf("Expected only the prod environment, got %v", repos["svc-orders"])
+	}
+	if len(prod.Variables) != 1 || prod.Variables["LOG_LEVEL"] != "warn" {
+		t.Errorf("Expected only LOG_LEVEL, got %v", prod.Variables)
+	}
+	if prod.Secrets["TOKEN"] != "x" {
+		t.Errorf("Expected the TOKEN secret to be kept, got %v", prod.Secrets)
+	}
+	if len(cfg.Orgs["MarkDevOps"].Repos) != 3 {
+		t.Errorf("Expected the original config to be left untouched")
+	}
+
+	named := config.Filter{Names: []string{"TOKEN"}}.Apply(cfg).Orgs["MarkDevOps"].Repos
+	if len(named) != 1 || len(named["svc-orders"]) != 1 || named["svc-orders"]["prod"].Secrets["TOKEN"] != "x" {
+		t.Errorf("Expected only environments with a matching name to be kept, got %v", named)
+	}
+
+	exclude := config.Filter{Exclude: []string{"*/dev", "*/prod/DEBUG"}}
+	if exclude.MatchEnv("svc-orders", "dev") || !exclude.MatchEnv("svc-orders", "prod") {
+		t.Errorf("Expected */dev to exclude dev environments only")
+	}
+	if exclude.MatchName("svc-orders", "prod", "debug") || !exclude.MatchName("svc-orders", "prod", "LOG_LEVEL") {
+		t.Errorf("Expected */prod/DEBUG to exclude the DEBUG variable only")
+	}
+	if !(config.Filter{Repos: []string{"MarkDevOps/svc-*"}}).MatchRepo("MarkDevOps", "svc-orders") {
+		t.Errorf("Expected org/repo patterns to match")
+	}
+}
+
+// Writing test to check --type selects resources by name, alias, ALL and secrets-variables, and --name narrows them
+func TestSelectResources(t *testing.T) {
+	cases := map[string]string{
+		"ALL":                   "env,secrets,variables,branch-policies",
+		"all":                   "env,secrets,variables,branch-policies",
+		"vars, deployment-env":  "env,variables",
+		"secrets-variables":     "secrets,variables",
+		"branch-policy,secret,": "secrets,branch-policies",
+	}
+	for typeFlag, want := range cases {
+		resources, err := config.SelectResources(typeFlag, config.Filter{})
+		if err != nil {
+			t.Errorf("%q: Expected no error, got %v", typeFlag, err)
+			continue
+		}
+		if got := strings.Join(resources, ","); got != want {
+			t.Errorf("%q: Expected %s, got %s", typeFlag, want, got)
+		}
+	}
+
+	for _, typeFlag := range []string{"", "env,bogus"} {
+		if _, err := config.SelectResources(typeFlag, config.Filter{}); err == nil {
+			t.Errorf("%q: Expected an error", typeFlag)
+		}
+	}
+
+	named := config.Filter{Names: []string{"LOG_LEVEL"}}
+	if resources, err := config.SelectResources("ALL", named); err != nil || strings.Join(resources, ",") != "secrets,variables" {
+		t.Errorf("Expected --name to keep only secrets and variables, got %v, %v", resources, err)
+	}
+	if _, err := config.SelectResources("env,branch-policies", named); err == nil {
+		t.Errorf("Expected an error when --name leaves nothing to create")
+	}
+}
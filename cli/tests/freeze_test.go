@@ -0,0 +1,78 @@
+package api_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/freeze"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check recurring and absolute freezes are active at the right times and places
+func TestFreezeActive(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	freezes := []types.Freeze{
+		{Name: "friday", Schedule: "0 15 * * 5", Duration: "57h", Timezone: "Europe/London", Environments: []string{"prod"}},
+		{Name: "holidays", Start: "2025-12-20", End: "2026-01-05", Timezone: "Europe/London", Repos: []string{"svc-*"}},
+	}
+
+	friday := time.Date(2025, 10, 17, 16, 0, 0, 0, london)
+	if active := freeze.Active(freezes, "MarkDevOps", "AutoGit", "prod", friday); len(active) != 1 || active[0].Freeze.Name != "friday" {
+		t.Errorf("Expected the friday freeze to be active on Friday afternoon, got %v", active)
+	}
+	if active := freeze.Active(freezes, "MarkDevOps", "AutoGit", "prod", friday.Add(-2*time.Hour)); len(active) != 0 {
+		t.Errorf("Expected no freeze on Friday morning, got %v", active)
+	}
+	if active := freeze.Active(freezes, "MarkDevOps", "AutoGit", "prod", time.Date(2025, 10, 19, 23, 0, 0, 0, london)); len(active) != 1 {
+		t.Errorf("Expected the friday freeze to last until Monday, got %v", active)
+	}
+	if active := freeze.Active(freezes, "MarkDevOps", "AutoGit", "dev", friday); len(active) != 0 {
+		t.Errorf("Expected dev not to be frozen, got %v", active)
+	}
+
+	christmas := time.Date(2025, 12, 24, 10, 0, 0, 0, london)
+	if active := freeze.Active(freezes, "MarkDevOps", "svc-orders", "dev", christmas); len(active) != 1 || active[0].Freeze.Name != "holidays" {
+		t.Errorf("Expected the holidays freeze for svc-orders, got %v", active)
+	}
+	if active := freeze.Active(freezes, "MarkDevOps", "AutoGit", "dev", christmas); len(active) != 0 {
+		t.Errorf("Expected AutoGit/dev not to be frozen over the holidays, got %v", active)
+	}
+
+	upcoming := freeze.Windows(freezes, friday.Add(-48*time.Hour), friday.AddDate(0, 0, 14))
+	if len(upcoming) != 3 {
+		t.Errorf("Expected three friday windows in the next two weeks, got %d", len(upcoming))
+	}
+}
+
+// Writing test to check invalid freezes are reported when loading the config
+func TestLoadFreezeValidation(t *testing.T) {
+	path := writeConfig(t, `
+org: MarkDevOps
+freezes:
+  - name: bad-cron
+    schedule: "0 25 * * 5"
+    duration: 2h
+  - name: bad-zone
+    start: 2025-12-20
+    end: 2025-12-19
+    timezone: Mars/Olympus
+repos:
+  AutoGit:
+    prod:
+      createDeploymentEnv: true
+`)
+	_, err := config.Load(path)
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, expected := range []string{"bad-cron", "hour", "unknown timezone"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %v", expected, err)
+		}
+	}
+}
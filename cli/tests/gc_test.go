@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/gc"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/preview"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check gc keeps configured environments' newest deployments and removes the rest
+func TestGCPolicy(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+	deployments := []types.Deployment{
+		{ID: 5, Environment: "prod", CreatedAt: daysAgo(200)},
+		{ID: 4, Environment: "prod", CreatedAt: daysAgo(300)},
+		{ID: 3, Environment: "Dev", CreatedAt: daysAgo(1)},
+		{ID: 2, Environment: "pr-41", CreatedAt: daysAgo(2)},
+		{ID: 1, Environment: "pr-12", CreatedAt: daysAgo(120)},
+	}
+	policy := gc.Policy{
+		Configured:       map[string]bool{"prod": true, "dev": true},
+		Retention:        90 * 24 * time.Hour,
+		IdleEnvironments: 30 * 24 * time.Hour,
+		Now:              now,
+	}
+
+	removed := make(map[int]string)
+	for _, candidate := range policy.Deployments(deployments) {
+		removed[candidate.Deployment.ID] = candidate.Reason
+	}
+	if len(removed) != 3 || removed[4] != "older than 90 days" || removed[2] != "environment not in config" || removed[1] == "" {
+		t.Errorf("Expected deployments 1, 2 and 4 to be removed, got %v", removed)
+	}
+
+	environments := []types.Environment{
+		{Name: "prod", CreatedAt: daysAgo(400).Format(time.RFC3339)},
+		{Name: "pr-41", CreatedAt: daysAgo(3).Format(time.RFC3339)},
+		{Name: "pr-12", CreatedAt: daysAgo(121).Format(time.RFC3339)},
+		{Name: "pr-7", CreatedAt: daysAgo(60).Format(time.RFC3339)},
+	}
+	var names []string
+	for _, candidate := range policy.Environments(environments, deployments) {
+		names = append(names, candidate.Environment.Name)
+	}
+	if len(names) != 2 || names[0] != "pr-12" || names[1] != "pr-7" {
+		t.Errorf("Expected pr-12 and pr-7 to be removed, got %v", names)
+	}
+}
+
+// Writing test to check gc leaves preview environments of open pull requests to preview gc
+func TestGCPolicyIgnoresPreviews(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	previews := types.Previews{}
+	policy := gc.Policy{
+		Configured:       map[string]bool{"prod": true},
+		IdleEnvironments: 30 * 24 * time.Hour,
+		Ignored: func(env string) bool {
+			_, ok := preview.PullNumber(previews, env)
+			return ok
+		},
+		Now: now,
+	}
+	deployments := []types.Deployment{
+		{ID: 2, Environment: "pr-41", CreatedAt: now.AddDate(0, 0, -90)},
+		{ID: 1, Environment: "staging-old", CreatedAt: now.AddDate(0, 0, -90)},
+	}
+	candidates := policy.Deployments(deployments)
+	if len(candidates) != 1 || candidates[0].Deployment.ID != 1 {
+		t.Errorf("Expected only the staging-old deployment to be removed, got %v", candidates)
+	}
+
+	environments := []types.Environment{
+		{Name: "PR-41", CreatedAt: now.AddDate(0, 0, -120).Format(time.RFC3339)},
+		{Name: "staging-old", CreatedAt: now.AddDate(0, 0, -120).Format(time.RFC3339)},
+	}
+	envCandidates := policy.Environments(environments, deployments)
+	if len(envCandidates) != 1 || envCandidates[0].Environment.Name != "staging-old" {
+		t.Errorf("Expected only staging-old to be removed, got %v", envCandidates)
+	}
+}
@@ -0,0 +1,76 @@
+package api_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check expressions in values are interpolated
+func TestInterpolate(t *testing.T) {
+	os.Setenv("AUTOGIT_TEST_REGION", "eu-west-1")
+	defer os.Unsetenv("AUTOGIT_TEST_REGION")
+
+	scope := config.NewScope("MarkDevOps", "AutoGit", "Dev", types.Values{
+		"DOMAIN":  "example.com",
+		"APP_URL": "https://${{ lower(env.name) }}.${{ repo.name }}.${{ vars.domain }}",
+	})
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"${{ vars.APP_URL }}", "https://dev.AutoGit.example.com"},
+		{"${{ org }}/${{ upper(repo.name) }}", "MarkDevOps/AUTOGIT"},
+		{"${{ process.env.AUTOGIT_TEST_REGION }}", "eu-west-1"},
+		{"${{ default(vars.MISSING, process.env.AUTOGIT_TEST_UNSET, 'fallback') }}", "fallback"},
+		{"no expressions", "no expressions"},
+		{"$${{ github.sha }}", "${{ github.sha }}"},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			result, err := scope.Interpolate(test.value)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, result)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"${{ vars.MISSING }}", "${{ secrets.X }}", "${{ trim(env.name) }}", "${{ env.name", "${{ lower(env.name }}"} {
+		if _, err := scope.Interpolate(invalid); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+
+	loop := config.NewScope("MarkDevOps", "AutoGit", "Dev", types.Values{"A": "${{ vars.B }}", "B": "${{ vars.A }}"})
+	if _, err := loop.Interpolate("${{ vars.A }}"); err == nil {
+		t.Errorf("Expected an error for variables referring to each other")
+	}
+}
+
+// Writing test to check undefined references are reported when the config is loaded
+func TestLoadInterpolationErrors(t *testing.T) {
+	path := writeConfig(t, `org: MarkDevOps
+repos:
+  AutoGit:
+    dev:
+      variables:
+        APP_URL: https://${{ env.name }}.${{ vars.DOMAIN }}
+`)
+
+	_, err := config.Load(path)
+	var validationErrors config.ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) != 1 {
+		t.Fatalf("Expected one validation error, got %v", err)
+	}
+	if validationErrors[0].Line != 6 || !strings.Contains(validationErrors[0].Message, "undefined reference") {
+		t.Errorf("Expected undefined reference on line 6, got %v", validationErrors[0])
+	}
+}
@@ -0,0 +1,88 @@
+package api_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/preview"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check preview environment names map back to pull request numbers
+func TestPreviewPullNumber(t *testing.T) {
+	previews := types.Previews{}
+	if name := preview.EnvironmentName(previews, 123); name != "pr-123" {
+		t.Errorf("expected pr-123, got %s", name)
+	}
+	cases := map[string]int{"pr-123": 123, "PR-7": 7, "pr-": 0, "pr-007": 0, "pr-12a": 0, "prod": 0, "review-5": 0}
+	for env, want := range cases {
+		number, ok := preview.PullNumber(previews, env)
+		if ok != (want > 0) || number != want {
+			t.Errorf("PullNumber(%q) = %d, %v; want %d", env, number, ok, want)
+		}
+	}
+	if number, ok := preview.PullNumber(types.Previews{Prefix: "review-"}, "review-5"); !ok || number != 5 {
+		t.Errorf("expected review-5 to be pull request 5, got %d, %v", number, ok)
+	}
+}
+
+// Writing test to check the previews environment is resolved from templates and restricted to the head branch
+func TestPreviewEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `
+environmentTemplates:
+  preview:
+    createVariables: true
+    variables:
+      LOG_LEVEL: debug
+    branchPolicies:
+      - name: main
+orgs:
+  acme:
+    repos:
+      web: {}
+previews:
+  repos: ["web*"]
+  environment:
+    extends: preview
+    variables:
+      APP_URL: https://${{ env.name }}.example.com
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	previews := cfg.Orgs["acme"].Previews
+	if previews == nil {
+		t.Fatal("expected the top-level previews to be given to acme")
+	}
+	if !preview.AllowsRepo(*previews, "Web-App") || preview.AllowsRepo(*previews, "api") {
+		t.Errorf("expected previews for web* repositories only")
+	}
+
+	envOptions := preview.Environment(*previews, "feature/login")
+	if err := config.InterpolateEnvironment("acme", "web", "pr-9", &envOptions); err != nil {
+		t.Fatal(err)
+	}
+	if !envOptions.CreateDeploymentEnv || !envOptions.CreateVariables {
+		t.Errorf("expected the environment and variables to be created, got %+v", envOptions)
+	}
+	if envOptions.Variables["LOG_LEVEL"] != "debug" || envOptions.Variables["APP_URL"] != "https://pr-9.example.com" {
+		t.Errorf("unexpected variables %v", envOptions.Variables)
+	}
+	if len(envOptions.BranchPolicies) != 1 || envOptions.BranchPolicies[0].Name != "feature/login" {
+		t.Errorf("expected only the head branch policy, got %v", envOptions.BranchPolicies)
+	}
+	if envOptions.DeploymentBranchPolicy == nil || !envOptions.DeploymentBranchPolicy.CustomBranchPolicies {
+		t.Errorf("expected custom branch policies, got %v", envOptions.DeploymentBranchPolicy)
+	}
+	if previews.Environment.Variables["APP_URL"] != "https://${{ env.name }}.example.com" {
+		t.Errorf("expected the config to be left unchanged, got %v", previews.Environment.Variables)
+	}
+}
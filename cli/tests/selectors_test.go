@@ -0,0 +1,63 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/config"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+)
+
+// Writing test to check repository selectors expand into the matching repositories
+func TestExpandRepoSelectors(t *testing.T) {
+	org := types.OrgConfig{
+		Repos: map[string]map[string]types.DeploymentEnvOptions{
+			"svc-orders": {"prod": {WaitTimer: ptr(60)}},
+		},
+		RepoSelectors: []types.RepoSelector{{
+			Match: types.RepoMatch{
+				Names:            []string{"svc-*"},
+				Topics:           []string{"microservice"},
+				Team:             "platform",
+				CustomProperties: map[string]string{"tier": "prod"},
+				ExcludeArchived:  true,
+			},
+			Environments: map[string]types.DeploymentEnvOptions{
+				"dev":  {CreateDeploymentEnv: true},
+				"prod": {CreateDeploymentEnv: true, WaitTimer: ptr(30)},
+			},
+		}},
+	}
+
+	matching := func(name string) types.Repository {
+		return types.Repository{Name: name, Topics: []string{"Microservice"}, CustomProperties: map[string]string{"tier": "prod"}}
+	}
+	archived := matching("svc-archived")
+	archived.Archived = true
+	wrongTier := matching("svc-tier")
+	wrongTier.CustomProperties = map[string]string{"tier": "dev"}
+	noTopic := matching("svc-topic")
+	noTopic.Topics = nil
+
+	repos := []types.Repository{matching("SVC-Orders"), matching("svc-payments"), matching("web-frontend"), matching("svc-other-team"), archived, wrongTier, noTopic}
+	teamRepos := map[string]map[string]bool{
+		"platform": {"svc-orders": true, "svc-payments": true, "web-frontend": true, "svc-archived": true, "svc-tier": true, "svc-topic": true},
+	}
+
+	config.ExpandRepoSelectors(&org, repos, teamRepos)
+
+	if len(org.Repos) != 2 {
+		t.Fatalf("Expected svc-orders and svc-payments, got %v", org.Repos)
+	}
+	if _, ok := org.Repos["svc-payments"]["dev"]; !ok {
+		t.Errorf("Expected svc-payments to get the dev environment")
+	}
+	if org.Repos["svc-orders"]["prod"].WaitTimer == nil || *org.Repos["svc-orders"]["prod"].WaitTimer != 60 {
+		t.Errorf("Expected the explicit svc-orders prod environment to win over the selector")
+	}
+	if _, ok := org.Repos["svc-orders"]["dev"]; !ok {
+		t.Errorf("Expected svc-orders to get the dev environment from the selector")
+	}
+	if org.RepoSelectors != nil {
+		t.Errorf("Expected selectors to be cleared once expanded")
+	}
+}
@@ -32,3 +32,28 @@ func TestSetHeader(t *testing.T) {
 		}
 	})
 }
+
+// Writing test to check that an organization's token never falls back to another variable or host
+func TestToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "gh_default")
+	t.Setenv("OTHER_ORG_TOKEN", "")
+
+	api.RegisterOrg("TokenOrg", "", "")
+	if token, err := api.Token("TokenOrg"); err != nil || token != "gh_default" {
+		t.Errorf("Expected GITHUB_TOKEN for api.github.com, got %q, %v", token, err)
+	}
+
+	api.RegisterOrg("TokenOrg", "", "OTHER_ORG_TOKEN")
+	if token, err := api.Token("TokenOrg"); err == nil {
+		t.Errorf("Expected an error for an unset tokenEnv, got %q", token)
+	}
+	t.Setenv("OTHER_ORG_TOKEN", "gh_other")
+	if token, err := api.Token("TokenOrg"); err != nil || token != "gh_other" {
+		t.Errorf("Expected the tokenEnv token, got %q, %v", token, err)
+	}
+
+	api.RegisterOrg("TokenOrg", "https://github.example.com/api/v3", "")
+	if token, err := api.Token("TokenOrg"); err == nil {
+		t.Errorf("Expected an error for another API host without tokenEnv, got %q", token)
+	}
+}
@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/state"
+)
+
+// Writing test to check secret fingerprints survive a save and reload of the state file
+func TestSecretState(t *testing.T) {
+	os.Setenv(state.KeyEnv, "test-key")
+	defer os.Unsetenv(state.KeyEnv)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	key := state.SecretKey("org", "repo", "dev", "secret1")
+
+	st, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if st.Unchanged(key, "value", "2025-01-01T00:00:00Z") {
+		t.Errorf("Expected unknown secret to be reported as changed")
+	}
+
+	st.Record(key, "value", "2025-01-01T00:00:00Z")
+	if err := st.Save(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	reloaded, err := state.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("same value and updated_at", func(t *testing.T) {
+		if !reloaded.Unchanged(key, "value", "2025-01-01T00:00:00Z") {
+			t.Errorf("Expected secret to be unchanged")
+		}
+	})
+
+	t.Run("different value", func(t *testing.T) {
+		if reloaded.Unchanged(key, "other", "2025-01-01T00:00:00Z") {
+			t.Errorf("Expected secret with a new value to be changed")
+		}
+	})
+
+	t.Run("updated outside AutoGit", func(t *testing.T) {
+		if reloaded.Unchanged(key, "value", "2025-02-01T00:00:00Z") {
+			t.Errorf("Expected secret updated in GitHub to be changed")
+		}
+	})
+
+	t.Run("value not stored in plain text", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(data) == 0 || strings.Contains(string(data), "value") {
+			t.Errorf("Expected fingerprint, got %s", data)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/MarkDevOps/AutoGit/cli/pkg/types"
+	"github.com/MarkDevOps/AutoGit/cli/pkg/watch"
+)
+
+// Writing test to check watch only reports deployments and statuses that changed after the first poll
+func TestTrackerEvents(t *testing.T) {
+	tracker := watch.NewTracker()
+	old := types.Deployment{ID: 1, Ref: "v1.0.0", Environment: "prod"}
+	status := func(id int, state string) []types.DeploymentStatus {
+		return []types.DeploymentStatus{{ID: id, State: state}}
+	}
+
+	if events := tracker.Deployments("MarkDevOps", "AutoGit", []types.Deployment{old}); len(events) != 0 {
+		t.Errorf("Expected the first poll to be a silent baseline, got %v", events)
+	}
+	if events := tracker.Statuses("MarkDevOps", "AutoGit", 1, status(10, "success")); len(events) != 0 {
+		t.Errorf("Expected baseline statuses to be silent, got %v", events)
+	}
+
+	next := types.Deployment{ID: 2, Ref: "v1.1.0", Environment: "prod"}
+	events := tracker.Deployments("MarkDevOps", "AutoGit", []types.Deployment{next, old})
+	if len(events) != 1 || events[0].Type != "deployment" || events[0].Ref != "v1.1.0" {
+		t.Fatalf("Expected one new deployment event, got %v", events)
+	}
+
+	events = tracker.Statuses("MarkDevOps", "AutoGit", 2, status(20, "in_progress"))
+	if len(events) != 1 || events[0].State != "in_progress" || events[0].Environment != "prod" {
+		t.Errorf("Expected the first status of a new deployment, got %v", events)
+	}
+	if events := tracker.Statuses("MarkDevOps", "AutoGit", 2, status(20, "in_progress")); len(events) != 0 {
+		t.Errorf("Expected an unchanged status to be silent, got %v", events)
+	}
+	if events := tracker.Statuses("MarkDevOps", "AutoGit", 1, status(11, "inactive")); len(events) != 1 || events[0].State != "inactive" {
+		t.Errorf("Expected a status change of a baseline deployment, got %v", events)
+	}
+}